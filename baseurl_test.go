@@ -0,0 +1,47 @@
+package gorequests_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/chyroc/gorequests"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_BaseURL(t *testing.T) {
+	as := assert.New(t)
+
+	t.Run("resolve", func(t *testing.T) {
+		fac := gorequests.NewFactory(gorequests.WithBaseURL("https://api.example.com/v2/"))
+		for _, v := range [][2]string{
+			{"users/1", "https://api.example.com/v2/users/1"},
+			{"/users/1", "https://api.example.com/users/1"},
+			{"../v1/users", "https://api.example.com/v1/users"},
+			{"", "https://api.example.com/v2/"},
+			{"?a=1", "https://api.example.com/v2/?a=1"},
+			{"https://other.com/x", "https://other.com/x"},
+		} {
+			as.Equal(v[1], fac.New(http.MethodGet, v[0]).RequestFullURL(), v[0])
+		}
+
+		as.Equal("https://api.example.com/users/1", gorequests.New(http.MethodGet, "users/1").WithBaseURL("https://api.example.com/v2").RequestFullURL())
+		as.Equal("https://api.example.com/v2/users/a%2Fb", fac.New(http.MethodGet, "users/{id}").WithPathParam("id", "a/b").RequestFullURL())
+	})
+
+	t.Run("default query", func(t *testing.T) {
+		fac := gorequests.NewFactory(
+			gorequests.WithBaseURL(joinHttpBinURL("/")),
+			gorequests.WithDefaultQuery("token", "t"),
+			gorequests.WithDefaultQuery("lang", "en"),
+			gorequests.WithLogger(gorequests.NewDiscardLogger()),
+		)
+
+		as.Equal(joinHttpBinURL("/get?lang=en&token=t"), fac.New(http.MethodGet, "get").RequestFullURL())
+		as.Equal(joinHttpBinURL("/get?lang=zh&token=t"), fac.New(http.MethodGet, "get?lang=zh").RequestFullURL())
+		as.Equal(joinHttpBinURL("/get?lang=en&token=x"), fac.New(http.MethodGet, "get").WithQuery("token", "x").RequestFullURL())
+
+		val, err := fac.New(http.MethodGet, "get").Get("args.token")
+		as.Nil(err)
+		as.Equal("t", val.String())
+	})
+}
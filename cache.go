@@ -0,0 +1,513 @@
+package gorequests
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CacheHeader is set to "1" in response header, when response is served from cache
+const CacheHeader = "X-From-Cache"
+
+// CacheEntry is the cached response
+type CacheEntry struct {
+	Status       int         `json:"status"`
+	Header       http.Header `json:"header"`
+	Body         []byte      `json:"body"`
+	VaryHeader   http.Header `json:"vary_header"` // request header listed in Vary
+	RequestTime  time.Time   `json:"request_time"`
+	ResponseTime time.Time   `json:"response_time"`
+}
+
+// CacheStorage store CacheEntry, Get return nil entry and nil error when key not exist
+type CacheStorage interface {
+	Get(key string) (*CacheEntry, error)
+	Set(key string, entry *CacheEntry) error
+	Delete(key string) error
+}
+
+// Cache is a private HTTP cache follow RFC 7234
+//
+// it honors Cache-Control, Expires, Vary, ETag and Last-Modified, revalidate stale response with
+// If-None-Match/If-Modified-Since, and support stale-while-revalidate and stale-if-error.
+type Cache struct {
+	storage CacheStorage
+	now     func() time.Time
+}
+
+// NewCache create Cache with storage
+func NewCache(storage CacheStorage) *Cache {
+	return &Cache{storage: storage, now: time.Now}
+}
+
+// RoundTripper wrap rt, serve response from cache when possible
+//
+// cacheable response is stored when its body is read to EOF, streaming response like text/event-stream is never stored.
+func (r *Cache) RoundTripper(rt http.RoundTripper) http.RoundTripper {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	return &cacheRoundTripper{cache: r, transport: rt}
+}
+
+type cacheRoundTripper struct {
+	cache     *Cache
+	transport http.RoundTripper
+}
+
+func (lf *cacheRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		resp, err := lf.transport.RoundTrip(req)
+		if err == nil && !isSafeMethod(req.Method) && resp.StatusCode < 400 {
+			// RFC 7234 4.4, unsafe request invalidate cached response
+			_ = lf.cache.storage.Delete(cacheKey(http.MethodGet, req.URL.String()))
+			_ = lf.cache.storage.Delete(cacheKey(http.MethodHead, req.URL.String()))
+		}
+		return resp, err
+	}
+
+	key := cacheKey(req.Method, req.URL.String())
+	reqCC := parseCacheControl(req.Header.Values("Cache-Control"))
+	if _, ok := reqCC["no-store"]; ok {
+		return lf.transport.RoundTrip(req)
+	}
+	_, reqNoCache := reqCC["no-cache"]
+	if strings.Contains(strings.ToLower(req.Header.Get("Pragma")), "no-cache") {
+		reqNoCache = true
+	}
+
+	entry, _ := lf.cache.storage.Get(key)
+	if entry != nil && !entry.matchVary(req) {
+		entry = nil
+	}
+
+	if entry == nil {
+		if _, ok := reqCC["only-if-cached"]; ok {
+			return newStaticResponse(req, http.StatusGatewayTimeout, nil, nil), nil
+		}
+		return lf.send(req, key, nil)
+	}
+
+	now := lf.cache.now()
+	respCC := parseCacheControl(entry.Header.Values("Cache-Control"))
+	age := entry.age(now)
+	lifetime := entry.freshnessLifetime(respCC)
+	if v, ok := reqCC.duration("max-age"); ok && v < lifetime {
+		lifetime = v
+	}
+	if v, ok := reqCC.duration("min-fresh"); ok {
+		age += v
+	}
+	_, respNoCache := respCC["no-cache"]
+	_, mustRevalidate := respCC["must-revalidate"]
+
+	if !reqNoCache && !respNoCache {
+		if age < lifetime {
+			return entry.response(req, now), nil
+		}
+		if v, ok := reqCC["max-stale"]; ok && !mustRevalidate {
+			if maxStale, err := strconv.Atoi(v); err != nil || age-lifetime <= time.Duration(maxStale)*time.Second {
+				return entry.response(req, now), nil
+			}
+		}
+		if v, ok := respCC.duration("stale-while-revalidate"); ok && !mustRevalidate && age-lifetime <= v {
+			go func() {
+				resp, err := lf.send(req.Clone(context.Background()), key, entry)
+				if err == nil {
+					// response is stored when body is read to EOF
+					_, _ = io.Copy(ioutil.Discard, resp.Body)
+					_ = resp.Body.Close()
+				}
+			}()
+			return entry.response(req, now), nil
+		}
+	}
+	if _, ok := reqCC["only-if-cached"]; ok {
+		return newStaticResponse(req, http.StatusGatewayTimeout, nil, nil), nil
+	}
+
+	resp, err := lf.send(req, key, entry)
+	if (err != nil || resp.StatusCode >= 500) && !mustRevalidate {
+		staleIfError, ok := respCC.duration("stale-if-error")
+		if v, reqOK := reqCC.duration("stale-if-error"); reqOK {
+			staleIfError, ok = v, true
+		}
+		if ok && age-lifetime <= staleIfError {
+			if err == nil {
+				_ = resp.Body.Close()
+			}
+			return entry.response(req, now), nil
+		}
+	}
+	return resp, err
+}
+
+// send request, revalidate with entry if it is not nil, and store cacheable response
+func (lf *cacheRoundTripper) send(req *http.Request, key string, entry *CacheEntry) (*http.Response, error) {
+	if entry != nil {
+		etag, lastModified := entry.Header.Get("ETag"), entry.Header.Get("Last-Modified")
+		if etag != "" || lastModified != "" {
+			req = req.Clone(req.Context())
+			if etag != "" {
+				req.Header.Set("If-None-Match", etag)
+			}
+			if lastModified != "" {
+				req.Header.Set("If-Modified-Since", lastModified)
+			}
+		}
+	}
+
+	requestTime := lf.cache.now()
+	resp, err := lf.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	responseTime := lf.cache.now()
+
+	if entry != nil && resp.StatusCode == http.StatusNotModified {
+		_ = resp.Body.Close()
+		// RFC 7234 4.3.4, update stored response with 304 response header
+		header := entry.Header.Clone()
+		for k, v := range resp.Header {
+			if k == "Content-Length" {
+				continue
+			}
+			header[k] = v
+		}
+		updated := &CacheEntry{
+			Status:       entry.Status,
+			Header:       header,
+			Body:         entry.Body,
+			VaryHeader:   entry.VaryHeader,
+			RequestTime:  requestTime,
+			ResponseTime: responseTime,
+		}
+		_ = lf.cache.storage.Set(key, updated)
+		return updated.response(req, responseTime), nil
+	}
+
+	if !isCacheableResponse(req, resp) {
+		return resp, nil
+	}
+
+	vary := http.Header{}
+	for _, k := range varyKeys(resp.Header) {
+		vary[k] = req.Header.Values(k)
+	}
+	header := resp.Header.Clone()
+	store := func(body []byte) {
+		_ = lf.cache.storage.Set(key, &CacheEntry{
+			Status:       resp.StatusCode,
+			Header:       header,
+			Body:         body,
+			VaryHeader:   vary,
+			RequestTime:  requestTime,
+			ResponseTime: responseTime,
+		})
+	}
+	if !hasResponseBody(req.Method, resp) {
+		store(nil)
+		return resp, nil
+	}
+
+	// response is stored when body is read to EOF, so body is not buffered before returned
+	resp.Body = newTeeBody(resp.Body, func(body []byte, complete bool) error {
+		if complete {
+			store(body)
+		}
+		return nil
+	})
+	return resp, nil
+}
+
+// response create http.Response from entry
+func (r *CacheEntry) response(req *http.Request, now time.Time) *http.Response {
+	header := r.Header.Clone()
+	header.Set("Age", strconv.Itoa(int(r.age(now)/time.Second)))
+	header.Set(CacheHeader, "1")
+	body := r.Body
+	if req.Method == http.MethodHead {
+		body = nil
+	}
+	resp := newStaticResponse(req, r.Status, header, body)
+	if req.Method == http.MethodHead {
+		resp.Header.Del("Content-Length")
+		resp.ContentLength = int64(len(r.Body))
+		if v := r.Header.Get("Content-Length"); v != "" {
+			resp.Header.Set("Content-Length", v)
+		}
+	}
+	return resp
+}
+
+func (r *CacheEntry) matchVary(req *http.Request) bool {
+	for k, v := range r.VaryHeader {
+		if strings.Join(req.Header.Values(k), ",") != strings.Join(v, ",") {
+			return false
+		}
+	}
+	return true
+}
+
+// age is current_age of RFC 7234 4.2.3
+func (r *CacheEntry) age(now time.Time) time.Duration {
+	apparentAge := time.Duration(0)
+	if date, err := http.ParseTime(r.Header.Get("Date")); err == nil && r.ResponseTime.After(date) {
+		apparentAge = r.ResponseTime.Sub(date)
+	}
+	correctedAge := r.ResponseTime.Sub(r.RequestTime)
+	if v, err := strconv.Atoi(r.Header.Get("Age")); err == nil && v > 0 {
+		correctedAge += time.Duration(v) * time.Second
+	}
+	if apparentAge > correctedAge {
+		correctedAge = apparentAge
+	}
+	return correctedAge + now.Sub(r.ResponseTime)
+}
+
+// freshnessLifetime is freshness_lifetime of RFC 7234 4.2.1
+func (r *CacheEntry) freshnessLifetime(cc cacheControl) time.Duration {
+	if v, ok := cc.duration("max-age"); ok {
+		return v
+	}
+	date, err := http.ParseTime(r.Header.Get("Date"))
+	if err != nil {
+		date = r.ResponseTime
+	}
+	if v := r.Header.Get("Expires"); v != "" {
+		expires, err := http.ParseTime(v)
+		if err != nil {
+			return 0 // invalid Expires means already expired
+		}
+		return expires.Sub(date)
+	}
+	// RFC 7234 4.2.2, heuristic freshness
+	if lastModified, err := http.ParseTime(r.Header.Get("Last-Modified")); err == nil && date.After(lastModified) {
+		return date.Sub(lastModified) / 10
+	}
+	return 0
+}
+
+func isCacheableResponse(req *http.Request, resp *http.Response) bool {
+	switch resp.StatusCode {
+	case 200, 203, 204, 300, 301, 308, 404, 405, 410, 414, 501:
+	default:
+		return false
+	}
+	if _, ok := parseCacheControl(req.Header.Values("Cache-Control"))["no-store"]; ok {
+		return false
+	}
+	cc := parseCacheControl(resp.Header.Values("Cache-Control"))
+	if _, ok := cc["no-store"]; ok {
+		return false
+	}
+	for _, k := range varyKeys(resp.Header) {
+		if k == "*" {
+			return false
+		}
+	}
+	// streaming response never end, or is too large to store
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); isStreamMediaType(mediaType) {
+		return false
+	}
+	// response without freshness or validator can not be reused
+	if _, ok := cc["max-age"]; ok {
+		return true
+	}
+	return resp.Header.Get("Expires") != "" || resp.Header.Get("ETag") != "" || resp.Header.Get("Last-Modified") != ""
+}
+
+func isStreamMediaType(mediaType string) bool {
+	switch mediaType {
+	case "text/event-stream", "application/x-ndjson", "application/stream+json", "multipart/x-mixed-replace":
+		return true
+	}
+	return false
+}
+
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+func varyKeys(header http.Header) []string {
+	var keys []string
+	for _, v := range header.Values("Vary") {
+		for _, k := range strings.Split(v, ",") {
+			if k = strings.TrimSpace(k); k != "" {
+				keys = append(keys, http.CanonicalHeaderKey(k))
+			}
+		}
+	}
+	return keys
+}
+
+func cacheKey(method, url string) string {
+	return method + " " + url
+}
+
+// cacheControl is directive to value map of Cache-Control header
+type cacheControl map[string]string
+
+func parseCacheControl(values []string) cacheControl {
+	cc := cacheControl{}
+	for _, v := range values {
+		for _, part := range strings.Split(v, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			if i := strings.Index(part, "="); i >= 0 {
+				cc[strings.ToLower(strings.TrimSpace(part[:i]))] = strings.Trim(strings.TrimSpace(part[i+1:]), `"`)
+			} else {
+				cc[strings.ToLower(part)] = ""
+			}
+		}
+	}
+	return cc
+}
+
+func (r cacheControl) duration(key string) (time.Duration, bool) {
+	v, ok := r[key]
+	if !ok {
+		return 0, false
+	}
+	i, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || i < 0 {
+		return 0, false
+	}
+	return time.Duration(i) * time.Second, true
+}
+
+// NewMemoryCacheStorage create in-memory LRU CacheStorage, capacity <= 0 means unlimited
+func NewMemoryCacheStorage(capacity int) CacheStorage {
+	return &memoryCacheStorage{
+		capacity: capacity,
+		list:     list.New(),
+		items:    map[string]*list.Element{},
+	}
+}
+
+type memoryCacheStorage struct {
+	lock     sync.Mutex
+	capacity int
+	list     *list.List
+	items    map[string]*list.Element
+}
+
+type memoryCacheItem struct {
+	key   string
+	entry *CacheEntry
+}
+
+func (r *memoryCacheStorage) Get(key string) (*CacheEntry, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if e, ok := r.items[key]; ok {
+		r.list.MoveToFront(e)
+		return e.Value.(*memoryCacheItem).entry, nil
+	}
+	return nil, nil
+}
+
+func (r *memoryCacheStorage) Set(key string, entry *CacheEntry) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if e, ok := r.items[key]; ok {
+		e.Value.(*memoryCacheItem).entry = entry
+		r.list.MoveToFront(e)
+		return nil
+	}
+	r.items[key] = r.list.PushFront(&memoryCacheItem{key: key, entry: entry})
+	for r.capacity > 0 && r.list.Len() > r.capacity {
+		e := r.list.Back()
+		r.list.Remove(e)
+		delete(r.items, e.Value.(*memoryCacheItem).key)
+	}
+	return nil
+}
+
+func (r *memoryCacheStorage) Delete(key string) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if e, ok := r.items[key]; ok {
+		r.list.Remove(e)
+		delete(r.items, key)
+	}
+	return nil
+}
+
+// NewDiskCacheStorage create CacheStorage which store every entry as one json file in dir
+func NewDiskCacheStorage(dir string) CacheStorage {
+	return &diskCacheStorage{dir: dir}
+}
+
+type diskCacheStorage struct {
+	lock sync.RWMutex
+	dir  string
+}
+
+func (r *diskCacheStorage) Get(key string) (*CacheEntry, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	bs, err := ioutil.ReadFile(r.filename(key))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	entry := new(CacheEntry)
+	if err := json.Unmarshal(bs, entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (r *diskCacheStorage) Set(key string, entry *CacheEntry) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	bs, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(r.dir, 0o755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(r.filename(key), bs, 0o644)
+}
+
+func (r *diskCacheStorage) Delete(key string) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if err := os.Remove(r.filename(key)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (r *diskCacheStorage) filename(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(r.dir, hex.EncodeToString(sum[:])+".json")
+}
@@ -0,0 +1,155 @@
+package gorequests_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/chyroc/gorequests"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Cache(t *testing.T) {
+	as := assert.New(t)
+
+	var hits int32
+	var failing int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&hits, 1)
+		switch r.URL.Path {
+		case "/max-age":
+			w.Header().Set("Cache-Control", "max-age=60")
+		case "/etag":
+			w.Header().Set("Cache-Control", "no-cache")
+			w.Header().Set("ETag", `"v1"`)
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		case "/vary":
+			w.Header().Set("Cache-Control", "max-age=60")
+			w.Header().Set("Vary", "Accept-Language")
+			_, _ = fmt.Fprint(w, r.Header.Get("Accept-Language"))
+			return
+		case "/no-store":
+			w.Header().Set("Cache-Control", "no-store")
+		case "/no-validator":
+			w.Header().Set("Cache-Control", "no-cache")
+		case "/sse":
+			w.Header().Set("Cache-Control", "no-cache")
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Header().Set("ETag", `"v1"`)
+			_, _ = fmt.Fprintf(w, "data: %d\n\n", n)
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+			return
+		case "/stale-if-error":
+			if atomic.LoadInt32(&failing) == 1 {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			w.Header().Set("Cache-Control", "max-age=0, stale-if-error=60")
+		}
+		_, _ = fmt.Fprintf(w, "%d", n)
+	}))
+	defer server.Close()
+
+	for _, storage := range []gorequests.CacheStorage{gorequests.NewMemoryCacheStorage(10), gorequests.NewDiskCacheStorage(t.TempDir())} {
+		fac := gorequests.NewFactory(gorequests.WithLogger(gorequests.NewDiscardLogger()), gorequests.WithCache(gorequests.NewCache(storage)))
+		get := func(path string) *gorequests.Request {
+			return fac.New(http.MethodGet, server.URL+path)
+		}
+
+		t.Run("max-age", func(t *testing.T) {
+			atomic.StoreInt32(&hits, 0)
+			as.Equal("1", get("/max-age").MustText())
+			req := get("/max-age")
+			as.Equal("1", req.MustText())
+			as.True(req.MustResponseFromCache())
+			as.Equal("2", get("/max-age").WithHeader("Cache-Control", "no-cache").MustText())
+			as.Equal(int32(2), atomic.LoadInt32(&hits))
+
+			// unsafe method invalidate cache
+			fac.New(http.MethodPost, server.URL+"/max-age").MustText()
+			as.Equal("4", get("/max-age").MustText())
+		})
+
+		t.Run("etag", func(t *testing.T) {
+			atomic.StoreInt32(&hits, 0)
+			as.Equal("1", get("/etag").MustText())
+			req := get("/etag")
+			as.Equal("1", req.MustText())
+			as.True(req.MustResponseFromCache())
+			as.Equal(int32(2), atomic.LoadInt32(&hits))
+		})
+
+		t.Run("vary", func(t *testing.T) {
+			as.Equal("en", get("/vary").WithHeader("Accept-Language", "en").MustText())
+			as.Equal("zh", get("/vary").WithHeader("Accept-Language", "zh").MustText())
+			// response is stored when body is read to EOF
+			req := get("/vary").WithHeader("Accept-Language", "en")
+			as.Equal("en", req.MustText())
+			as.False(req.MustResponseFromCache())
+			as.True(get("/vary").WithHeader("Accept-Language", "en").MustResponseFromCache())
+		})
+
+		t.Run("no-store", func(t *testing.T) {
+			atomic.StoreInt32(&hits, 0)
+			as.Equal("1", get("/no-store").MustText())
+			as.Equal("2", get("/no-store").MustText())
+
+			// no-cache without validator is not stored
+			as.Equal("3", get("/no-validator").MustText())
+			as.Equal("4", get("/no-validator").MustText())
+		})
+
+		t.Run("stream", func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second*2)
+			defer cancel()
+
+			stop := errors.New("stop")
+			for i := 0; i < 2; i++ {
+				var events []string
+				err := get("/sse").WithContext(ctx).SSE(func(event gorequests.Event) error {
+					events = append(events, event.Data)
+					return stop
+				})
+				as.Equal(stop, err)
+				as.Len(events, 1)
+			}
+			as.Nil(ctx.Err())
+		})
+
+		t.Run("stale-if-error", func(t *testing.T) {
+			atomic.StoreInt32(&hits, 0)
+			atomic.StoreInt32(&failing, 0)
+			as.Equal("1", get("/stale-if-error").MustText())
+			atomic.StoreInt32(&failing, 1)
+			req := get("/stale-if-error")
+			as.Equal("1", req.MustText())
+			as.Equal(http.StatusOK, req.MustResponseStatus())
+			as.True(req.MustResponseFromCache())
+			as.Equal(int32(2), atomic.LoadInt32(&hits))
+		})
+	}
+
+	t.Run("only-if-cached", func(t *testing.T) {
+		fac := gorequests.NewFactory(gorequests.WithLogger(gorequests.NewDiscardLogger()), gorequests.WithCache(gorequests.NewCache(gorequests.NewMemoryCacheStorage(0))))
+		as.Equal(http.StatusGatewayTimeout, fac.New(http.MethodGet, server.URL+"/max-age").WithHeader("Cache-Control", "only-if-cached").MustResponseStatus())
+	})
+
+	t.Run("lru", func(t *testing.T) {
+		storage := gorequests.NewMemoryCacheStorage(1)
+		as.Nil(storage.Set("a", &gorequests.CacheEntry{ResponseTime: time.Now()}))
+		as.Nil(storage.Set("b", &gorequests.CacheEntry{ResponseTime: time.Now()}))
+		a, _ := storage.Get("a")
+		b, _ := storage.Get("b")
+		as.Nil(a)
+		as.NotNil(b)
+	})
+}
@@ -0,0 +1,369 @@
+package gorequests
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CassetteMode decide how Cassette handle request
+type CassetteMode int
+
+const (
+	CassetteModeRecord        CassetteMode = iota // always send request, and record all interactions
+	CassetteModeReplay                            // never send request, response with recorded interactions
+	CassetteModeRecordMissing                     // response with recorded interactions, send and record request not matched
+)
+
+// Interaction one recorded request and response
+type Interaction struct {
+	Request  *CassetteRequest  `json:"request" yaml:"request"`
+	Response *CassetteResponse `json:"response" yaml:"response"`
+}
+
+type CassetteRequest struct {
+	Method       string      `json:"method" yaml:"method"`
+	URL          string      `json:"url" yaml:"url"`
+	Header       http.Header `json:"header" yaml:"header"`
+	Body         string      `json:"body" yaml:"body"`
+	BodyEncoding string      `json:"body_encoding,omitempty" yaml:"body_encoding,omitempty"`
+}
+
+type CassetteResponse struct {
+	Status       int         `json:"status" yaml:"status"`
+	Header       http.Header `json:"header" yaml:"header"`
+	Body         string      `json:"body" yaml:"body"`
+	BodyEncoding string      `json:"body_encoding,omitempty" yaml:"body_encoding,omitempty"`
+}
+
+// CassetteMatcher report whether req(with read body) match recorded interaction
+type CassetteMatcher func(req *http.Request, body []byte, i *Interaction) bool
+
+// CassetteHook is called before interaction is saved, can be used to scrub secret
+type CassetteHook func(i *Interaction) error
+
+// MatchMethod match request method
+func MatchMethod(req *http.Request, body []byte, i *Interaction) bool {
+	return req.Method == i.Request.Method
+}
+
+// MatchURL match request full url, contain query
+func MatchURL(req *http.Request, body []byte, i *Interaction) bool {
+	return req.URL.String() == i.Request.URL
+}
+
+// MatchBody match request body
+func MatchBody(req *http.Request, body []byte, i *Interaction) bool {
+	bs, err := decodeCassetteBody(i.Request.Body, i.Request.BodyEncoding)
+	return err == nil && bytes.Equal(body, bs)
+}
+
+// MatchHeaders match request header of keys
+func MatchHeaders(keys ...string) CassetteMatcher {
+	return func(req *http.Request, body []byte, i *Interaction) bool {
+		for _, k := range keys {
+			if strings.Join(req.Header.Values(k), ",") != strings.Join(i.Request.Header.Values(k), ",") {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// ScrubHeaders replace request and response header value of keys with replacement
+func ScrubHeaders(replacement string, keys ...string) CassetteHook {
+	return func(i *Interaction) error {
+		for _, k := range keys {
+			for _, header := range []http.Header{i.Request.Header, i.Response.Header} {
+				if header.Get(k) != "" {
+					header.Set(k, replacement)
+				}
+			}
+		}
+		return nil
+	}
+}
+
+// Cassette record interactions to file, and replay them later
+//
+// file is stored as yaml if filename has suffix .yaml or .yml, or json otherwise.
+type Cassette struct {
+	lock         sync.Mutex
+	filename     string
+	mode         CassetteMode
+	matchers     []CassetteMatcher
+	hooks        []CassetteHook
+	interactions []*Interaction
+	used         map[*Interaction]bool
+	pending      []*cassettePending // response body not read to EOF or closed yet
+}
+
+// cassettePending is interaction waiting for response body
+type cassettePending struct {
+	body        *teeBody
+	interaction func(respBody []byte) *Interaction
+}
+
+// NewCassette create Cassette, and load recorded interactions from filename when mode is not CassetteModeRecord
+func NewCassette(filename string, mode CassetteMode) (*Cassette, error) {
+	r := &Cassette{
+		filename: filename,
+		mode:     mode,
+		matchers: []CassetteMatcher{MatchMethod, MatchURL},
+		used:     map[*Interaction]bool{},
+	}
+	if mode == CassetteModeRecord {
+		return r, nil
+	}
+
+	bs, err := ioutil.ReadFile(filename)
+	if err != nil {
+		if os.IsNotExist(err) && mode == CassetteModeRecordMissing {
+			return r, nil
+		}
+		return nil, fmt.Errorf("[gorequest] load cassette %s failed: %w", filename, err)
+	}
+	if r.isYAML() {
+		err = yaml.Unmarshal(bs, &r.interactions)
+	} else {
+		err = json.Unmarshal(bs, &r.interactions)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("[gorequest] load cassette %s failed: %w", filename, err)
+	}
+	return r, nil
+}
+
+// WithMatcher set matchers used to find recorded interaction, default is MatchMethod and MatchURL
+func (r *Cassette) WithMatcher(matchers ...CassetteMatcher) *Cassette {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.matchers = matchers
+	return r
+}
+
+// WithHook add hooks called before interaction is saved
+func (r *Cassette) WithHook(hooks ...CassetteHook) *Cassette {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.hooks = append(r.hooks, hooks...)
+	return r
+}
+
+// Interactions return recorded interactions
+func (r *Cassette) Interactions() []*Interaction {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return append([]*Interaction{}, r.interactions...)
+}
+
+// Save write recorded interactions to file
+//
+// interaction whose response body is still being read is saved with body read so far.
+func (r *Cassette) Save() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.save()
+}
+
+// RoundTripper wrap rt, record or replay interactions according to mode
+//
+// new interaction is recorded when response body is read to EOF, response closed before EOF is not recorded,
+// response without body is recorded at once.
+func (r *Cassette) RoundTripper(rt http.RoundTripper) http.RoundTripper {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	return &cassetteRoundTripper{cassette: r, transport: rt}
+}
+
+func (r *Cassette) match(req *http.Request, body []byte) *Interaction {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	var last *Interaction
+	for _, v := range r.interactions {
+		if v.Request == nil || v.Response == nil || !r.isMatch(req, body, v) {
+			continue
+		}
+		last = v
+		if !r.used[v] {
+			r.used[v] = true
+			return v
+		}
+	}
+	return last
+}
+
+func (r *Cassette) isMatch(req *http.Request, body []byte, i *Interaction) bool {
+	for _, m := range r.matchers {
+		if !m(req, body, i) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *Cassette) record(i *Interaction) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if err := r.runHooks(i); err != nil {
+		return err
+	}
+	r.interactions = append(r.interactions, i)
+	r.used[i] = true
+	return r.save()
+}
+
+func (r *Cassette) addPending(p *cassettePending) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.pending = append(r.pending, p)
+}
+
+// finishPending remove p, and record interaction if body is read to EOF
+func (r *Cassette) finishPending(p *cassettePending, respBody []byte, complete bool) error {
+	r.lock.Lock()
+	for i, v := range r.pending {
+		if v == p {
+			r.pending = append(r.pending[:i], r.pending[i+1:]...)
+			break
+		}
+	}
+	r.lock.Unlock()
+
+	if !complete {
+		return nil
+	}
+	return r.record(p.interaction(respBody))
+}
+
+func (r *Cassette) runHooks(i *Interaction) error {
+	for _, hook := range r.hooks {
+		if err := hook(i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Cassette) save() error {
+	interactions := r.interactions
+	for _, v := range r.pending {
+		i := v.interaction(v.body.bytes())
+		if err := r.runHooks(i); err != nil {
+			return fmt.Errorf("[gorequest] save cassette %s failed: %w", r.filename, err)
+		}
+		interactions = append(interactions[:len(interactions):len(interactions)], i)
+	}
+
+	var bs []byte
+	var err error
+	if r.isYAML() {
+		bs, err = yaml.Marshal(interactions)
+	} else {
+		bs, err = json.MarshalIndent(interactions, "", "  ")
+	}
+	if err != nil {
+		return fmt.Errorf("[gorequest] save cassette %s failed: %w", r.filename, err)
+	}
+	if dir := filepath.Dir(r.filename); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("[gorequest] save cassette %s failed: %w", r.filename, err)
+		}
+	}
+	if err := ioutil.WriteFile(r.filename, bs, 0o644); err != nil {
+		return fmt.Errorf("[gorequest] save cassette %s failed: %w", r.filename, err)
+	}
+	return nil
+}
+
+func (r *Cassette) isYAML() bool {
+	ext := strings.ToLower(filepath.Ext(r.filename))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+type cassetteRoundTripper struct {
+	cassette  *Cassette
+	transport http.RoundTripper
+}
+
+func (lf *cassetteRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if lf.cassette.mode != CassetteModeRecord {
+		if i := lf.cassette.match(req, body); i != nil {
+			respBody, err := decodeCassetteBody(i.Response.Body, i.Response.BodyEncoding)
+			if err != nil {
+				return nil, err
+			}
+			return newStaticResponse(req, i.Response.Status, i.Response.Header.Clone(), respBody), nil
+		}
+		if lf.cassette.mode == CassetteModeReplay {
+			return nil, fmt.Errorf("[gorequest] cassette %s: no interaction match %s %s", lf.cassette.filename, req.Method, req.URL.String())
+		}
+	}
+
+	resp, err := lf.transport.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+	header := resp.Header.Clone()
+	newInteraction := func(respBody []byte) *Interaction {
+		i := &Interaction{
+			Request: &CassetteRequest{
+				Method: req.Method,
+				URL:    req.URL.String(),
+				Header: req.Header.Clone(),
+			},
+			Response: &CassetteResponse{
+				Status: resp.StatusCode,
+				Header: header,
+			},
+		}
+		i.Request.Body, i.Request.BodyEncoding = toHARText(body)
+		i.Response.Body, i.Response.BodyEncoding = toHARText(respBody)
+		return i
+	}
+	if !hasResponseBody(req.Method, resp) {
+		if err := lf.cassette.record(newInteraction(nil)); err != nil {
+			_ = resp.Body.Close()
+			return nil, err
+		}
+		return resp, nil
+	}
+
+	// interaction is recorded when body is read to EOF, so streaming response is not blocked
+	p := &cassettePending{interaction: newInteraction}
+	p.body = newTeeBody(resp.Body, func(respBody []byte, complete bool) error {
+		return lf.cassette.finishPending(p, respBody, complete)
+	})
+	lf.cassette.addPending(p)
+	resp.Body = p.body
+	return resp, nil
+}
+
+func decodeCassetteBody(body, encoding string) ([]byte, error) {
+	if encoding == "base64" {
+		return base64.StdEncoding.DecodeString(body)
+	}
+	return []byte(body), nil
+}
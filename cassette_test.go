@@ -0,0 +1,148 @@
+package gorequests_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chyroc/gorequests"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Cassette(t *testing.T) {
+	as := assert.New(t)
+
+	count := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count++
+		bs, _ := ioutil.ReadAll(r.Body)
+		w.Header().Set("Set-Cookie", "token=secret")
+		_, _ = w.Write(append([]byte(r.URL.Path+":"), bs...))
+	}))
+	defer server.Close()
+
+	for _, ext := range []string{".yaml", ".json"} {
+		t.Run(ext, func(t *testing.T) {
+			count = 0
+			file := filepath.Join(t.TempDir(), "cassette"+ext)
+
+			cassette, err := gorequests.NewCassette(file, gorequests.CassetteModeRecord)
+			as.Nil(err)
+			cassette.WithHook(gorequests.ScrubHeaders("***", "Authorization", "Set-Cookie"))
+			fac := gorequests.NewFactory(gorequests.WithLogger(gorequests.NewDiscardLogger()), gorequests.WithCassette(cassette))
+			as.Equal("/a:1", fac.New(http.MethodPost, server.URL+"/a").WithHeader("Authorization", "token").WithBody("1").MustText())
+			as.Equal("/b:", fac.New(http.MethodGet, server.URL+"/b").MustText())
+			as.Equal(2, count)
+
+			bs, err := ioutil.ReadFile(file)
+			as.Nil(err)
+			as.False(strings.Contains(string(bs), "secret"))
+			as.False(strings.Contains(string(bs), "token"))
+
+			// replay
+			cassette, err = gorequests.NewCassette(file, gorequests.CassetteModeReplay)
+			as.Nil(err)
+			cassette.WithMatcher(gorequests.MatchMethod, gorequests.MatchURL, gorequests.MatchBody)
+			fac = gorequests.NewFactory(gorequests.WithLogger(gorequests.NewDiscardLogger()), gorequests.WithCassette(cassette))
+			as.Equal("/a:1", fac.New(http.MethodPost, server.URL+"/a").WithBody("1").MustText())
+			_, err = fac.New(http.MethodPost, server.URL+"/a").WithBody("2").Text()
+			as.NotNil(err)
+			as.Contains(err.Error(), "no interaction match")
+			as.Equal(2, count)
+
+			// record missing
+			cassette, err = gorequests.NewCassette(file, gorequests.CassetteModeRecordMissing)
+			as.Nil(err)
+			fac = gorequests.NewFactory(gorequests.WithLogger(gorequests.NewDiscardLogger()), gorequests.WithCassette(cassette))
+			as.Equal("/b:", fac.New(http.MethodGet, server.URL+"/b").MustText())
+			as.Equal("/c:", fac.New(http.MethodGet, server.URL+"/c").MustText())
+			as.Equal(3, count)
+			as.Len(cassette.Interactions(), 3)
+		})
+	}
+}
+
+func Test_CassetteUnreadBody(t *testing.T) {
+	as := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Path", r.URL.Path)
+		_, _ = w.Write([]byte("body"))
+	}))
+	defer server.Close()
+
+	file := filepath.Join(t.TempDir(), "cassette.json")
+	cassette, err := gorequests.NewCassette(file, gorequests.CassetteModeRecord)
+	as.Nil(err)
+	fac := gorequests.NewFactory(gorequests.WithLogger(gorequests.NewDiscardLogger()), gorequests.WithCassette(cassette))
+
+	// HEAD response has no body, and is recorded at once
+	as.Equal("/head", fac.New(http.MethodHead, server.URL+"/head").MustResponseHeaderByKey("X-Path"))
+	as.Len(cassette.Interactions(), 1)
+	// body of response is never read, and is flushed by Save
+	as.Equal(http.StatusOK, fac.New(http.MethodGet, server.URL+"/unread").MustResponseStatus())
+	as.Nil(cassette.Save())
+
+	cassette, err = gorequests.NewCassette(file, gorequests.CassetteModeReplay)
+	as.Nil(err)
+	as.Len(cassette.Interactions(), 2)
+	fac = gorequests.NewFactory(gorequests.WithLogger(gorequests.NewDiscardLogger()), gorequests.WithCassette(cassette))
+	as.Equal("/head", fac.New(http.MethodHead, server.URL+"/head").MustResponseHeaderByKey("X-Path"))
+	as.Equal(http.StatusOK, fac.New(http.MethodGet, server.URL+"/unread").MustResponseStatus())
+}
+
+func Test_CassetteStream(t *testing.T) {
+	as := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		for i := 0; i < 3; i++ {
+			_, _ = fmt.Fprintf(w, "{\"id\":%d}\n", i)
+			w.(http.Flusher).Flush()
+			time.Sleep(time.Millisecond * 100)
+		}
+	}))
+	defer server.Close()
+
+	file := filepath.Join(t.TempDir(), "stream.json")
+	cassette, err := gorequests.NewCassette(file, gorequests.CassetteModeRecordMissing)
+	as.Nil(err)
+
+	// first line arrive before whole response is written
+	start := time.Now()
+	var first time.Duration
+	var lines []string
+	as.Nil(gorequests.New(http.MethodGet, server.URL).WithCassette(cassette).WithLogger(gorequests.NewDiscardLogger()).EachJSONLine(func(raw json.RawMessage) error {
+		if first == 0 {
+			first = time.Since(start)
+		}
+		lines = append(lines, string(raw))
+		return nil
+	}))
+	as.Less(int64(first), int64(time.Millisecond*100))
+	as.Equal([]string{`{"id":0}`, `{"id":1}`, `{"id":2}`}, lines)
+	as.Len(cassette.Interactions(), 1)
+
+	// stream closed before EOF is not recorded
+	cassette, err = gorequests.NewCassette(filepath.Join(t.TempDir(), "partial.json"), gorequests.CassetteModeRecord)
+	as.Nil(err)
+	stop := errors.New("stop")
+	as.Equal(stop, gorequests.New(http.MethodGet, server.URL).WithCassette(cassette).WithLogger(gorequests.NewDiscardLogger()).EachJSONLine(func(raw json.RawMessage) error {
+		return stop
+	}))
+	as.Len(cassette.Interactions(), 0)
+
+	// replay
+	cassette, err = gorequests.NewCassette(file, gorequests.CassetteModeReplay)
+	as.Nil(err)
+	text, err := gorequests.New(http.MethodGet, server.URL).WithCassette(cassette).WithLogger(gorequests.NewDiscardLogger()).Text()
+	as.Nil(err)
+	as.Equal("{\"id\":0}\n{\"id\":1}\n{\"id\":2}\n", text)
+}
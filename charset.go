@@ -0,0 +1,78 @@
+package gorequests
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html/charset"
+	"golang.org/x/text/encoding"
+)
+
+// decodeText transcode response body to utf-8
+//
+// charset is decided by: WithResponseCharset, charset param of Content-Type, BOM, <meta charset> of html.
+// body is returned as-is, when it's not text or is already valid utf-8.
+func (r *Request) decodeText(bs []byte) (string, error) {
+	enc, err := r.responseEncoding(bs)
+	if err != nil {
+		return "", err
+	}
+	if enc == nil {
+		return string(bytes.TrimPrefix(bs, utf8BOM)), nil
+	}
+
+	res, err := enc.NewDecoder().Bytes(bs)
+	if err != nil {
+		return "", fmt.Errorf("[gorequest] %s %s decode response charset failed: %w", r.method, r.cachedurl, err)
+	}
+	return string(bytes.TrimPrefix(res, utf8BOM)), nil
+}
+
+// responseEncoding return nil, when no need to transcode
+func (r *Request) responseEncoding(bs []byte) (encoding.Encoding, error) {
+	if r.responseCharset != "" {
+		return lookupCharset(r.responseCharset)
+	}
+
+	contentType := r.resp.Header.Get("Content-Type")
+	mediaType, params, _ := mime.ParseMediaType(contentType)
+	if name := params["charset"]; name != "" {
+		if enc, err := lookupCharset(name); err == nil {
+			return enc, nil
+		}
+	}
+	if !isTextMediaType(mediaType) {
+		return nil, nil
+	}
+
+	// BOM, <meta charset> of html, or windows-1252 as default
+	enc, name, certain := charset.DetermineEncoding(bs, "text/html")
+	if name == "utf-8" || (!certain && utf8.Valid(bs)) {
+		return nil, nil
+	}
+	return enc, nil
+}
+
+func lookupCharset(name string) (encoding.Encoding, error) {
+	enc, canonical := charset.Lookup(name)
+	if enc == nil {
+		return nil, fmt.Errorf("unsupported charset: %s", name)
+	}
+	if canonical == "utf-8" {
+		return nil, nil
+	}
+	return enc, nil
+}
+
+func isTextMediaType(mediaType string) bool {
+	return mediaType == "" ||
+		strings.HasPrefix(mediaType, "text/") ||
+		strings.HasSuffix(mediaType, "+xml") ||
+		mediaType == "application/xml" ||
+		mediaType == "application/xhtml+xml"
+}
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
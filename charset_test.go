@@ -0,0 +1,53 @@
+package gorequests_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chyroc/gorequests"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+)
+
+func Test_Charset(t *testing.T) {
+	as := assert.New(t)
+
+	encode := func(enc encoding.Encoding, s string) string {
+		bs, _ := enc.NewEncoder().Bytes([]byte(s))
+		return string(bs)
+	}
+	cases := map[string]struct {
+		contentType string
+		body        string
+	}{
+		"/gbk":       {"text/plain; charset=gbk", encode(simplifiedchinese.GBK, "你好")},
+		"/shift_jis": {"text/html", encode(japanese.ShiftJIS, `<meta charset="shift_jis">こんにちは`)},
+		"/big5":      {"text/plain", encode(traditionalchinese.Big5, "你好")},
+		"/latin1":    {"text/plain; charset=iso-8859-1", encode(charmap.ISO8859_1, "café")},
+		"/bom":       {"text/plain", "\xef\xbb\xbf你好"},
+		"/binary":    {"application/octet-stream", "\xff\xfe\xfd"},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c := cases[r.URL.Path]
+		w.Header().Set("Content-Type", c.contentType)
+		_, _ = w.Write([]byte(c.body))
+	}))
+	defer server.Close()
+
+	fac := gorequests.NewFactory(gorequests.WithLogger(gorequests.NewDiscardLogger()))
+
+	as.Equal("你好", fac.New(http.MethodGet, server.URL+"/gbk").MustText())
+	as.Equal(`<meta charset="shift_jis">こんにちは`, fac.New(http.MethodGet, server.URL+"/shift_jis").MustText())
+	as.Equal("你好", fac.New(http.MethodGet, server.URL+"/big5").WithResponseCharset("big5").MustText())
+	as.Equal("café", fac.New(http.MethodGet, server.URL+"/latin1").MustText())
+	as.Equal("你好", fac.New(http.MethodGet, server.URL+"/bom").MustText())
+	as.Equal("\xff\xfe\xfd", fac.New(http.MethodGet, server.URL+"/binary").MustText())
+
+	_, err := fac.New(http.MethodGet, server.URL+"/gbk").WithResponseCharset("not-exist").Text()
+	as.NotNil(err)
+}
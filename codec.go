@@ -0,0 +1,207 @@
+package gorequests
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"mime"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/proto"
+	"gopkg.in/yaml.v3"
+)
+
+// Codec encode and decode body of one media type
+type Codec interface {
+	// ContentType is set to request Content-Type header, when body is encoded by this codec
+	ContentType() string
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// builtin codecs
+var (
+	JSONCodec     Codec = jsonCodec{}
+	XMLCodec      Codec = xmlCodec{}
+	YAMLCodec     Codec = yamlCodec{}
+	FormCodec     Codec = formCodec{}
+	MsgpackCodec  Codec = msgpackCodec{}
+	CBORCodec     Codec = cborCodec{}
+	ProtobufCodec Codec = protobufCodec{}
+)
+
+var (
+	codecLock sync.RWMutex
+	codecMap  = map[string]Codec{
+		"application/json":                  JSONCodec,
+		"text/json":                         JSONCodec,
+		"application/xml":                   XMLCodec,
+		"text/xml":                          XMLCodec,
+		"application/yaml":                  YAMLCodec,
+		"application/x-yaml":                YAMLCodec,
+		"text/yaml":                         YAMLCodec,
+		"text/x-yaml":                       YAMLCodec,
+		"application/x-www-form-urlencoded": FormCodec,
+		"application/msgpack":               MsgpackCodec,
+		"application/x-msgpack":             MsgpackCodec,
+		"application/vnd.msgpack":           MsgpackCodec,
+		"application/cbor":                  CBORCodec,
+		"application/protobuf":              ProtobufCodec,
+		"application/x-protobuf":            ProtobufCodec,
+		"application/vnd.google.protobuf":   ProtobufCodec,
+	}
+)
+
+// RegisterCodec register codec of media type, used by Unmarshal to decode response with same Content-Type,
+// and by WithBody to encode body of request with same Content-Type
+func RegisterCodec(mediaType string, codec Codec) {
+	codecLock.Lock()
+	defer codecLock.Unlock()
+
+	codecMap[strings.ToLower(mediaType)] = codec
+}
+
+// LookupCodec find codec by Content-Type, structured syntax suffix like +json and +xml is supported
+func LookupCodec(contentType string) (Codec, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	}
+
+	codecLock.RLock()
+	defer codecLock.RUnlock()
+
+	if codec, ok := codecMap[mediaType]; ok {
+		return codec, true
+	}
+	if i := strings.LastIndex(mediaType, "+"); i >= 0 {
+		if codec, ok := codecMap["application/"+mediaType[i+1:]]; ok {
+			return codec, true
+		}
+	}
+	return nil, false
+}
+
+// json
+type jsonCodec struct{}
+
+func (jsonCodec) ContentType() string                        { return "application/json" }
+func (jsonCodec) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+
+// xml
+type xmlCodec struct{}
+
+func (xmlCodec) ContentType() string                   { return "application/xml" }
+func (xmlCodec) Marshal(v interface{}) ([]byte, error) { return xml.Marshal(v) }
+
+func (xmlCodec) Unmarshal(data []byte, v interface{}) error {
+	return newXMLDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// yaml
+type yamlCodec struct{}
+
+func (yamlCodec) ContentType() string                        { return "application/yaml" }
+func (yamlCodec) Marshal(v interface{}) ([]byte, error)      { return yaml.Marshal(v) }
+func (yamlCodec) Unmarshal(data []byte, v interface{}) error { return yaml.Unmarshal(data, v) }
+
+// msgpack
+type msgpackCodec struct{}
+
+func (msgpackCodec) ContentType() string                        { return "application/msgpack" }
+func (msgpackCodec) Marshal(v interface{}) ([]byte, error)      { return msgpack.Marshal(v) }
+func (msgpackCodec) Unmarshal(data []byte, v interface{}) error { return msgpack.Unmarshal(data, v) }
+
+// cbor
+type cborCodec struct{}
+
+func (cborCodec) ContentType() string                        { return "application/cbor" }
+func (cborCodec) Marshal(v interface{}) ([]byte, error)      { return cbor.Marshal(v) }
+func (cborCodec) Unmarshal(data []byte, v interface{}) error { return cbor.Unmarshal(data, v) }
+
+// protobuf, value must be proto.Message
+type protobufCodec struct{}
+
+func (protobufCodec) ContentType() string { return "application/x-protobuf" }
+
+func (protobufCodec) Marshal(v interface{}) ([]byte, error) {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("need proto.Message, but got %T", v)
+	}
+	return proto.Marshal(msg)
+}
+
+func (protobufCodec) Unmarshal(data []byte, v interface{}) error {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("need proto.Message, but got %T", v)
+	}
+	return proto.Unmarshal(data, msg)
+}
+
+// form, support: url.Values, map[string][]string, map[string]string, map[string]interface{}
+type formCodec struct{}
+
+func (formCodec) ContentType() string { return "application/x-www-form-urlencoded" }
+
+func (formCodec) Marshal(v interface{}) ([]byte, error) {
+	u := url.Values{}
+	switch v := v.(type) {
+	case url.Values:
+		u = v
+	case map[string][]string:
+		u = v
+	case map[string]string:
+		for k, vv := range v {
+			u.Set(k, vv)
+		}
+	case map[string]interface{}:
+		for k, vv := range v {
+			u.Set(k, fmt.Sprint(vv))
+		}
+	default:
+		return nil, fmt.Errorf("unsupported form type: %T", v)
+	}
+	return []byte(u.Encode()), nil
+}
+
+func (formCodec) Unmarshal(data []byte, v interface{}) error {
+	u, err := url.ParseQuery(string(data))
+	if err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case *url.Values:
+		*v = u
+	case *map[string][]string:
+		*v = u
+	case *map[string]string:
+		if *v == nil {
+			*v = map[string]string{}
+		}
+		for k := range u {
+			(*v)[k] = u.Get(k)
+		}
+	case *map[string]interface{}:
+		if *v == nil {
+			*v = map[string]interface{}{}
+		}
+		for k, vv := range u {
+			if len(vv) == 1 {
+				(*v)[k] = vv[0]
+			} else {
+				(*v)[k] = vv
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported form type: %T", v)
+	}
+	return nil
+}
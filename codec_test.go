@@ -0,0 +1,109 @@
+package gorequests_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chyroc/gorequests"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type codecUser struct {
+	Name string `json:"name" xml:"name" yaml:"name" msgpack:"name" cbor:"name"`
+	Age  int    `json:"age" xml:"age" yaml:"age" msgpack:"age" cbor:"age"`
+}
+
+type upperCodec struct{}
+
+func (upperCodec) ContentType() string { return "text/x-upper" }
+
+func (upperCodec) Marshal(v interface{}) ([]byte, error) { return []byte(v.(string)), nil }
+
+func (upperCodec) Unmarshal(data []byte, v interface{}) error {
+	*(v.(*string)) = "upper:" + string(data)
+	return nil
+}
+
+type userCodec struct{}
+
+func (userCodec) ContentType() string { return "text/x-user" }
+
+func (userCodec) Marshal(v interface{}) ([]byte, error) {
+	u := v.(codecUser)
+	return []byte(fmt.Sprintf("%s,%d", u.Name, u.Age)), nil
+}
+
+func (userCodec) Unmarshal(data []byte, v interface{}) error {
+	return fmt.Errorf("not implemented")
+}
+
+func Test_Codec(t *testing.T) {
+	as := assert.New(t)
+
+	// echo request body with request Content-Type
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bs, _ := ioutil.ReadAll(r.Body)
+		w.Header().Set("Content-Type", r.Header.Get("Content-Type"))
+		_, _ = w.Write(bs)
+	}))
+	defer server.Close()
+
+	fac := gorequests.NewFactory(gorequests.WithLogger(gorequests.NewDiscardLogger()))
+
+	for _, codec := range []gorequests.Codec{gorequests.JSONCodec, gorequests.XMLCodec, gorequests.YAMLCodec, gorequests.MsgpackCodec, gorequests.CBORCodec} {
+		t.Run(codec.ContentType(), func(t *testing.T) {
+			resp := codecUser{}
+			as.Nil(fac.New(http.MethodPost, server.URL).WithBodyAs(codec, codecUser{Name: "chyroc", Age: 18}).Unmarshal(&resp))
+			as.Equal(codecUser{Name: "chyroc", Age: 18}, resp)
+		})
+	}
+
+	t.Run("form", func(t *testing.T) {
+		m, err := fac.New(http.MethodPost, server.URL).WithBodyAs(gorequests.FormCodec, map[string]string{"a": "1"}).Map()
+		as.Nil(err)
+		as.Equal(map[string]interface{}{"a": "1"}, m)
+	})
+
+	t.Run("protobuf", func(t *testing.T) {
+		resp := &wrapperspb.StringValue{}
+		as.Nil(fac.New(http.MethodPost, server.URL).WithBodyAs(gorequests.ProtobufCodec, wrapperspb.String("hi")).Unmarshal(resp))
+		as.Equal("hi", resp.Value)
+	})
+
+	t.Run("suffix", func(t *testing.T) {
+		resp := codecUser{}
+		as.Nil(fac.New(http.MethodPost, server.URL).WithHeader("Content-Type", "application/vnd.api+json").WithBody(`{"name":"chyroc"}`).Unmarshal(&resp))
+		as.Equal("chyroc", resp.Name)
+	})
+
+	t.Run("register", func(t *testing.T) {
+		gorequests.RegisterCodec("text/x-upper", upperCodec{})
+		codec, ok := gorequests.LookupCodec("text/x-upper; charset=utf-8")
+		as.True(ok)
+		as.Equal(upperCodec{}, codec)
+
+		resp := ""
+		as.Nil(fac.New(http.MethodPost, server.URL).WithBodyAs(upperCodec{}, "hi").Unmarshal(&resp))
+		as.Equal("upper:hi", resp)
+
+		// WithBody encode body by codec of Content-Type
+		gorequests.RegisterCodec("text/x-user", userCodec{})
+		text, err := fac.New(http.MethodPost, server.URL).WithHeader("Content-Type", "text/x-user").WithBody(codecUser{Name: "chyroc", Age: 18}).Text()
+		as.Nil(err)
+		as.Equal("chyroc,18", text)
+
+		user := codecUser{}
+		as.Nil(fac.New(http.MethodPost, server.URL).WithHeader("Content-Type", "application/yaml").WithBody(codecUser{Name: "chyroc", Age: 18}).Unmarshal(&user))
+		as.Equal(codecUser{Name: "chyroc", Age: 18}, user)
+	})
+
+	t.Run("force", func(t *testing.T) {
+		resp := codecUser{}
+		as.Nil(fac.New(http.MethodPost, server.URL).WithHeader("Content-Type", "text/plain").WithBody("name: chyroc").UnmarshalAs(gorequests.YAMLCodec, &resp))
+		as.Equal("chyroc", resp.Name)
+	})
+}
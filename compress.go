@@ -0,0 +1,164 @@
+package gorequests
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// decodeContentEncoding decode body with Content-Encoding header values, support: gzip, deflate, br, zstd
+//
+// stacked encodings are decoded in reverse order, body with any unknown encoding or empty body is returned as-is.
+// decoded is true if body is encoded and all encodings are decoded.
+func decodeContentEncoding(encodings []string, body io.Reader) (_ io.Reader, decoded bool, _ error) {
+	list := splitContentEncoding(encodings)
+	if len(list) == 0 {
+		return body, false, nil
+	}
+	for _, v := range list {
+		if !isDecodableContentEncoding(v) {
+			return body, false, nil
+		}
+	}
+	br := bufio.NewReader(body)
+	if _, err := br.Peek(1); err == io.EOF {
+		return br, false, nil
+	}
+
+	body = br
+	for i := len(list) - 1; i >= 0; i-- {
+		var err error
+		switch list[i] {
+		case "gzip", "x-gzip":
+			body, err = gzip.NewReader(body)
+		case "deflate":
+			body, err = newDeflateReader(body)
+		case "br":
+			body = brotli.NewReader(body)
+		case "zstd":
+			var d *zstd.Decoder
+			d, err = zstd.NewReader(body)
+			if err == nil {
+				body = &zstdReader{decoder: d}
+			}
+		}
+		if err != nil {
+			return nil, false, err
+		}
+	}
+	return body, true, nil
+}
+
+func isDecodableContentEncoding(encoding string) bool {
+	switch encoding {
+	case "gzip", "x-gzip", "deflate", "br", "zstd":
+		return true
+	}
+	return false
+}
+
+// newDeflateReader read "deflate" body, which should be zlib format, but some server send raw deflate
+func newDeflateReader(body io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(body)
+	header, err := br.Peek(2)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	// zlib header: CMF(0x?8) and FLG, (CMF*256 + FLG) is multiple of 31
+	if len(header) == 2 && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(br)
+	}
+	return flate.NewReader(br), nil
+}
+
+// encodeContentEncoding compress body with encoding, rawBody is used if not nil, or body is compressed by stream
+func encodeContentEncoding(encoding string, rawBody []byte, body io.Reader) (io.Reader, error) {
+	if rawBody != nil {
+		buf := new(bytes.Buffer)
+		w, err := newContentEncoder(encoding, buf)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = w.Write(rawBody); err != nil {
+			return nil, err
+		}
+		if err = w.Close(); err != nil {
+			return nil, err
+		}
+		return buf, nil
+	}
+
+	pr, pw := io.Pipe()
+	w, err := newContentEncoder(encoding, pw)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		_, err := io.Copy(w, body)
+		if closeErr := w.Close(); err == nil {
+			err = closeErr
+		}
+		_ = pw.CloseWithError(err)
+	}()
+	return pr, nil
+}
+
+func newContentEncoder(encoding string, w io.Writer) (io.WriteCloser, error) {
+	switch encoding {
+	case "gzip":
+		return gzip.NewWriter(w), nil
+	case "deflate":
+		return zlib.NewWriter(w), nil
+	case "br":
+		return brotli.NewWriter(w), nil
+	case "zstd":
+		return zstd.NewWriter(w)
+	}
+	return nil, checkContentEncoding(encoding)
+}
+
+func checkContentEncoding(encoding string) error {
+	switch encoding {
+	case "gzip", "deflate", "br", "zstd":
+		return nil
+	}
+	return fmt.Errorf("unsupported content encoding: %q", encoding)
+}
+
+func splitContentEncoding(encodings []string) []string {
+	var list []string
+	for _, v := range encodings {
+		for _, e := range strings.Split(v, ",") {
+			e = strings.ToLower(strings.TrimSpace(e))
+			if e != "" && e != "identity" {
+				list = append(list, e)
+			}
+		}
+	}
+	return list
+}
+
+// zstdReader release resource of zstd.Decoder when read finished
+type zstdReader struct {
+	decoder *zstd.Decoder
+}
+
+func (r *zstdReader) Read(p []byte) (int, error) {
+	if r.decoder == nil {
+		return 0, io.EOF
+	}
+	n, err := r.decoder.Read(p)
+	if err != nil {
+		r.decoder.Close()
+		r.decoder = nil
+	}
+	return n, err
+}
@@ -0,0 +1,176 @@
+package gorequests_test
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/chyroc/gorequests"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Decompress(t *testing.T) {
+	as := assert.New(t)
+
+	encoders := map[string]func(w io.Writer) io.WriteCloser{
+		"gzip":       func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) },
+		"deflate":    func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) },
+		"rawdeflate": func(w io.Writer) io.WriteCloser { fw, _ := flate.NewWriter(w, flate.DefaultCompression); return fw },
+		"br":         func(w io.Writer) io.WriteCloser { return brotli.NewWriter(w) },
+		"zstd":       func(w io.Writer) io.WriteCloser { zw, _ := zstd.NewWriter(w); return zw },
+	}
+	encode := func(data []byte, encoding string) []byte {
+		buf := new(bytes.Buffer)
+		w := encoders[encoding](buf)
+		_, _ = w.Write(data)
+		_ = w.Close()
+		return buf.Bytes()
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encodings := strings.Split(r.URL.Query().Get("encoding"), ",")
+		body := []byte("hello world")
+		for _, v := range encodings {
+			body = encode(body, v)
+		}
+		w.Header().Set("Content-Encoding", strings.ReplaceAll(strings.Join(encodings, ", "), "rawdeflate", "deflate"))
+		_, _ = w.Write(body)
+	}))
+	defer server.Close()
+
+	fac := gorequests.NewFactory(gorequests.WithLogger(gorequests.NewDiscardLogger()), gorequests.WithHeader("Accept-Encoding", "gzip, deflate, br, zstd"))
+
+	for _, encoding := range []string{"gzip", "deflate", "rawdeflate", "br", "zstd", "gzip,br", "zstd,deflate,gzip"} {
+		t.Run(encoding, func(t *testing.T) {
+			req := fac.New(http.MethodGet, server.URL).WithQuery("encoding", encoding)
+			text, err := req.Text()
+			as.Nil(err)
+			as.Equal("hello world", text)
+			as.Equal("", req.MustResponseHeaderByKey("Content-Encoding"))
+			as.Equal("", req.MustResponseHeaderByKey("Content-Length"))
+		})
+	}
+
+	t.Run("raw", func(t *testing.T) {
+		req := fac.New(http.MethodGet, server.URL).WithQuery("encoding", "br").WithDecompress(false)
+		bs, err := req.Bytes()
+		as.Nil(err)
+		as.Equal(encode([]byte("hello world"), "br"), bs)
+		as.Equal("br", req.MustResponseHeaderByKey("Content-Encoding"))
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		// gzip is decoded only if foo is known, so body is returned as-is
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Encoding", "foo, gzip")
+			_, _ = w.Write(encode([]byte("hello world"), "gzip"))
+		}))
+		defer server.Close()
+
+		req := fac.New(http.MethodGet, server.URL)
+		bs, err := req.Bytes()
+		as.Nil(err)
+		as.Equal(encode([]byte("hello world"), "gzip"), bs)
+		as.Equal("foo, gzip", req.MustResponseHeaderByKey("Content-Encoding"))
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Encoding", "gzip")
+			status, _ := strconv.Atoi(r.URL.Query().Get("status"))
+			if status == 0 {
+				// empty body without Content-Length
+				w.(http.Flusher).Flush()
+				return
+			}
+			w.WriteHeader(status)
+		}))
+		defer server.Close()
+
+		for _, req := range []*gorequests.Request{
+			fac.New(http.MethodHead, server.URL),
+			fac.New(http.MethodGet, server.URL).WithQuery("status", "204"),
+			fac.New(http.MethodGet, server.URL).WithQuery("status", "304"),
+			fac.New(http.MethodGet, server.URL).WithQuery("status", "200"),
+			fac.New(http.MethodGet, server.URL),
+		} {
+			text, err := req.Text()
+			as.Nil(err, req.RequestFullURL())
+			as.Equal("", text)
+		}
+	})
+
+	t.Run("/gzip", func(t *testing.T) {
+		resp := struct {
+			Gzipped bool `json:"gzipped"`
+		}{}
+		as.Nil(fac.New(http.MethodGet, joinHttpBinURL("/gzip")).Unmarshal(&resp))
+		as.True(resp.Gzipped)
+	})
+}
+
+func Test_CompressedBody(t *testing.T) {
+	as := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Content-Encoding", r.Header.Get("Content-Encoding"))
+		w.Header().Set("X-Content-Length", strconv.FormatInt(r.ContentLength, 10))
+		body, err := decodeBody(r.Header.Get("Content-Encoding"), r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = io.Copy(w, body)
+	}))
+	defer server.Close()
+
+	fac := gorequests.NewFactory(gorequests.WithLogger(gorequests.NewDiscardLogger()))
+	data := strings.Repeat(`{"key":"value"}`, 100)
+
+	for _, encoding := range []string{"gzip", "zstd"} {
+		t.Run(encoding, func(t *testing.T) {
+			req := fac.New(http.MethodPost, server.URL).WithJSON(map[string]string{"key": "value"}).WithCompressedBody(encoding)
+			as.Equal(`{"key":"value"}`, req.MustText())
+			as.Equal(encoding, req.MustResponseHeaderByKey("X-Content-Encoding"))
+
+			// stream io.Reader body
+			req = fac.New(http.MethodPost, server.URL).WithBody(strings.NewReader(data)).WithCompressedBody(encoding)
+			as.Equal(data, req.MustText())
+			as.Equal("-1", req.MustResponseHeaderByKey("X-Content-Length"))
+
+			// request without body
+			req = fac.New(http.MethodGet, server.URL).WithCompressedBody(encoding)
+			as.Equal("", req.MustText())
+			as.Equal("", req.MustResponseHeaderByKey("X-Content-Encoding"))
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := fac.New(http.MethodPost, server.URL).WithBody("x").WithCompressedBody("lzma").Text()
+		as.NotNil(err)
+		as.Contains(err.Error(), "unsupported content encoding")
+	})
+}
+
+func decodeBody(encoding string, body io.Reader) (io.Reader, error) {
+	switch encoding {
+	case "gzip":
+		return gzip.NewReader(body)
+	case "zstd":
+		d, err := zstd.NewReader(body)
+		if err != nil {
+			return nil, err
+		}
+		return d.IOReadCloser(), nil
+	}
+	return body, nil
+}
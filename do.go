@@ -1,8 +1,10 @@
 package gorequests
 
 import (
+	"bytes"
 	"crypto/tls"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 )
@@ -18,7 +20,11 @@ func (r *Request) doInternalRequest() error {
 		return nil
 	}
 
-	r.cachedurl = r.parseRequestURL()
+	cachedurl, err := r.parseRequestURL()
+	r.cachedurl = cachedurl
+	if err != nil {
+		return err
+	}
 
 	r.logger.Info(r.Context(), "[gorequests] %s: %s, body=%s, header=%+v", r.method, r.cachedurl, r.rawBody, r.header)
 
@@ -30,23 +36,56 @@ func (r *Request) doInternalRequest() error {
 		}()
 	}
 
-	req, err := http.NewRequest(r.method, r.cachedurl, r.body)
+	req, err := r.newHTTPRequest(r.body)
+	if err != nil {
+		return err
+	}
+
+	resp, err := r.httpClient().Do(req)
+	r.resp = resp
+	r.isRequest = true
 	if err != nil {
-		return fmt.Errorf("[gorequest] %s %s new request failed: %w", r.method, r.cachedurl, err)
+		return fmt.Errorf("[gorequest] %s %s send request failed: %w", r.method, r.cachedurl, err)
+	}
+	return nil
+}
+
+// newHTTPRequest build http.Request with body, body is compressed and Content-Encoding is set if bodyEncoding is set
+//
+// rawBody is compressed instead of body if it is not nil, so body must be same as rawBody.
+func (r *Request) newHTTPRequest(body io.Reader) (*http.Request, error) {
+	encoded := r.bodyEncoding != "" && body != nil
+	if encoded {
+		var err error
+		if body, err = encodeContentEncoding(r.bodyEncoding, r.rawBody, body); err != nil {
+			return nil, fmt.Errorf("[gorequest] %s %s compress body failed: %w", r.method, r.cachedurl, err)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(r.Context(), r.method, r.cachedurl, body)
+	if err != nil {
+		// stop compress goroutine of stream body
+		if pr, ok := body.(*io.PipeReader); ok && encoded {
+			_ = pr.CloseWithError(err)
+		}
+		return nil, fmt.Errorf("[gorequest] %s %s new request failed: %w", r.method, r.cachedurl, err)
 	}
 
 	req.Header = r.header
+	if encoded {
+		req.Header = r.header.Clone()
+		req.Header.Set("Content-Encoding", r.bodyEncoding)
+	}
+	return req, nil
+}
 
+// httpClient build http.Client of request
+func (r *Request) httpClient() *http.Client {
 	// TODO: reuse client
 	c := &http.Client{
 		Timeout: r.timeout,
 	}
-	if r.isIgnoreSSL || r.wrapRoundTripperResponse != nil {
-		c.Transport = &wrapRoundTripper{
-			isIgnoreSSL: r.isIgnoreSSL,
-			wrapResp:    r.wrapRoundTripperResponse,
-		}
-	}
+	c.Transport = r.roundTripper()
 	if r.persistentJar != nil {
 		c.Jar = r.persistentJar
 	}
@@ -55,19 +94,12 @@ func (r *Request) doInternalRequest() error {
 			return http.ErrUseLastResponse
 		}
 	}
-
-	resp, err := c.Do(req)
-	r.resp = resp
-	r.isRequest = true
-	if err != nil {
-		return fmt.Errorf("[gorequest] %s %s send request failed: %w", r.method, r.cachedurl, err)
-	}
-	return nil
+	return c
 }
 
-// doRead send request and read response
+// doRead send request and read response, error is returned if body is already read by stream
 func (r *Request) doRead() error {
-	return r.doRequestFactor(func() error {
+	err := r.doRequestFactor(func() error {
 		if err := r.doInternalRequest(); err != nil {
 			return err
 		}
@@ -76,8 +108,13 @@ func (r *Request) doRead() error {
 			return nil
 		}
 
-		var err error
-		r.bytes, err = ioutil.ReadAll(r.resp.Body)
+		body, err := r.responseBody()
+		if err != nil {
+			r.isRead = true
+			return err
+		}
+
+		r.bytes, err = ioutil.ReadAll(body)
 		r.isRead = true
 		if err != nil {
 			return fmt.Errorf("[gorequest] %s %s read response failed: %w", r.method, r.cachedurl, err)
@@ -86,6 +123,71 @@ func (r *Request) doRead() error {
 		r.logger.Info(r.Context(), "[gorequests] %s: %s, doRead: %s", r.method, r.cachedurl, r.bytes)
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.isStreamed {
+		return r.streamedError()
+	}
+	return nil
+}
+
+// doStream send request and call f with response body, without buffering it
+//
+// body can not be read again after stream, and f is called without lock, so it can call other methods of request.
+func (r *Request) doStream(f func(body io.Reader) error) error {
+	if err := r.doRequest(); err != nil {
+		return err
+	}
+
+	r.lock.Lock()
+	if r.isStreamed {
+		r.lock.Unlock()
+		return r.streamedError()
+	}
+	if r.isRead {
+		bs := r.bytes
+		r.lock.Unlock()
+		return f(bytes.NewReader(bs))
+	}
+	r.isRead, r.isStreamed = true, true
+	body, err := r.responseBody()
+	r.lock.Unlock()
+
+	defer r.resp.Body.Close()
+
+	if err != nil {
+		return err
+	}
+	return f(body)
+}
+
+// streamedError is returned when response body is read again after stream
+func (r *Request) streamedError() error {
+	return fmt.Errorf("[gorequest] %s %s response body already read by stream", r.method, r.cachedurl)
+}
+
+// responseBody return response body, decoded with Content-Encoding
+//
+// Content-Encoding and Content-Length response headers are removed when body is decoded, like http.Transport does for gzip.
+func (r *Request) responseBody() (io.Reader, error) {
+	if r.isNoDecompress || !hasResponseBody(r.method, r.resp) {
+		return r.resp.Body, nil
+	}
+	body, decoded, err := decodeContentEncoding(r.resp.Header.Values("Content-Encoding"), r.resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("[gorequest] %s %s decode response failed: %w", r.method, r.cachedurl, err)
+	}
+	if decoded {
+		r.resp.Header.Del("Content-Encoding")
+		r.resp.Header.Del("Content-Length")
+		r.resp.ContentLength = -1
+		r.resp.Uncompressed = true
+	}
+	return body, nil
 }
 
 func (r *Request) doRequestFactor(f func() error) error {
@@ -100,15 +202,51 @@ func (r *Request) doRequestFactor(f func() error) error {
 	return r.err
 }
 
+// roundTripper build request http.RoundTripper, nil means http.DefaultTransport
+func (r *Request) roundTripper() http.RoundTripper {
+	var rt http.RoundTripper = r.transport
+	if r.isIgnoreSSL || r.wrapRoundTripperResponse != nil {
+		rt = &wrapRoundTripper{
+			transport:   r.transport,
+			isIgnoreSSL: r.isIgnoreSSL,
+			wrapResp:    r.wrapRoundTripperResponse,
+		}
+	}
+	if len(r.wrapTransports) > 0 && rt == nil {
+		rt = http.DefaultTransport
+	}
+	for _, f := range r.wrapTransports {
+		rt = f(rt)
+	}
+	return rt
+}
+
 type wrapRoundTripper struct {
+	transport   http.RoundTripper
 	isIgnoreSSL bool
 	wrapResp    func(resp *http.Response) (*http.Response, error)
 }
 
 func (lf wrapRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
-	var rt = &http.Transport{}
-	if lf.isIgnoreSSL {
-		rt.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	var rt = lf.transport
+	if rt == nil {
+		t := &http.Transport{}
+		if lf.isIgnoreSSL {
+			t.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+		}
+		rt = t
+	} else if lf.isIgnoreSSL {
+		// tls config of custom *http.Transport is cloned, other transport can not skip verify, so it is error
+		t, ok := rt.(*http.Transport)
+		if !ok {
+			return nil, fmt.Errorf("ignore ssl is not supported by transport %T", rt)
+		}
+		t = t.Clone()
+		if t.TLSClientConfig == nil {
+			t.TLSClientConfig = &tls.Config{}
+		}
+		t.TLSClientConfig.InsecureSkipVerify = true
+		rt = t
 	}
 	resp, err := rt.RoundTrip(req)
 	if err != nil {
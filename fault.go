@@ -0,0 +1,211 @@
+package gorequests
+
+import (
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Fault inject fault to request, next is used to send request
+type Fault func(req *http.Request, next http.RoundTripper) (*http.Response, error)
+
+// FaultRule decide which request and how often the fault is injected
+type FaultRule struct {
+	Host        string  // match request host, empty match all host
+	Path        string  // match request path prefix, empty match all path
+	Probability float64 // probability of inject fault, range: [0, 1], 0 means never, FaultAlways(1) means always
+	Fault       Fault
+}
+
+// FaultAlways is probability of always inject fault
+const FaultAlways float64 = 1
+
+// FaultInjector inject fault to request by rules, used for resilience testing
+type FaultInjector struct {
+	lock  sync.Mutex
+	rules []*FaultRule
+	rand  *rand.Rand
+}
+
+// NewFaultInjector create FaultInjector
+func NewFaultInjector() *FaultInjector {
+	return &FaultInjector{rand: rand.New(rand.NewSource(time.Now().UnixNano()))}
+}
+
+// WithSeed set random seed, to make injection reproducible
+func (r *FaultInjector) WithSeed(seed int64) *FaultInjector {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.rand = rand.New(rand.NewSource(seed))
+	return r
+}
+
+// AddRule add fault rule, all matched rules are applied in order
+func (r *FaultInjector) AddRule(rules ...*FaultRule) *FaultInjector {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.rules = append(r.rules, rules...)
+	return r
+}
+
+// Add add fault for all request with probability
+func (r *FaultInjector) Add(probability float64, fault Fault) *FaultInjector {
+	return r.AddRule(&FaultRule{Probability: probability, Fault: fault})
+}
+
+// RoundTripper wrap rt, inject fault before send request
+func (r *FaultInjector) RoundTripper(rt http.RoundTripper) http.RoundTripper {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	return &faultRoundTripper{injector: r, transport: rt}
+}
+
+func (r *FaultInjector) faults(req *http.Request) []Fault {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	var faults []Fault
+	for _, rule := range r.rules {
+		if rule.Fault == nil {
+			continue
+		}
+		if rule.Host != "" && !strings.EqualFold(rule.Host, req.URL.Host) && !strings.EqualFold(rule.Host, req.URL.Hostname()) {
+			continue
+		}
+		if rule.Path != "" && !strings.HasPrefix(req.URL.Path, rule.Path) {
+			continue
+		}
+		if rule.Probability <= 0 || (rule.Probability < FaultAlways && r.rand.Float64() >= rule.Probability) {
+			continue
+		}
+		faults = append(faults, rule.Fault)
+	}
+	return faults
+}
+
+type faultRoundTripper struct {
+	injector  *FaultInjector
+	transport http.RoundTripper
+}
+
+func (lf *faultRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt := lf.transport
+	faults := lf.injector.faults(req)
+	for i := len(faults) - 1; i >= 0; i-- {
+		rt = &faultNext{fault: faults[i], next: rt}
+	}
+	return rt.RoundTrip(req)
+}
+
+type faultNext struct {
+	fault Fault
+	next  http.RoundTripper
+}
+
+func (lf *faultNext) RoundTrip(req *http.Request) (*http.Response, error) {
+	return lf.fault(req, lf.next)
+}
+
+// FaultLatency delay d before send request
+func FaultLatency(d time.Duration) Fault {
+	return func(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+		return next.RoundTrip(req)
+	}
+}
+
+// FaultConnectionReset fail request with connection reset error, without send it
+func FaultConnectionReset() Fault {
+	return func(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+		return nil, &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}
+	}
+}
+
+// FaultTimeout fail request with timeout error, wait until request context is done if it has deadline
+func FaultTimeout() Fault {
+	return func(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+		if _, ok := req.Context().Deadline(); ok {
+			<-req.Context().Done()
+			return nil, req.Context().Err()
+		}
+		return nil, &net.OpError{Op: "dial", Net: "tcp", Err: faultTimeoutError{}}
+	}
+}
+
+// FaultStatus response with status code, without send request
+func FaultStatus(code int) Fault {
+	return func(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+		if req.Body != nil {
+			_ = req.Body.Close()
+		}
+		return newStaticResponse(req, code, nil, []byte(http.StatusText(code))), nil
+	}
+}
+
+// FaultTruncateBody send request, and response body is broken with io.ErrUnexpectedEOF after n bytes
+func FaultTruncateBody(n int) Fault {
+	return func(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+		resp, err := next.RoundTrip(req)
+		if err != nil {
+			return resp, err
+		}
+		resp.Body = &faultTruncateBody{body: resp.Body, remain: n}
+		return resp, nil
+	}
+}
+
+// FaultCorruptHeader send request, and replace response header value of keys with invalid value
+func FaultCorruptHeader(keys ...string) Fault {
+	return func(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+		resp, err := next.RoundTrip(req)
+		if err != nil {
+			return resp, err
+		}
+		for _, k := range keys {
+			resp.Header.Set(k, "\x00\xff corrupted")
+		}
+		return resp, nil
+	}
+}
+
+type faultTruncateBody struct {
+	body   io.ReadCloser
+	remain int
+}
+
+func (r *faultTruncateBody) Read(p []byte) (int, error) {
+	if r.remain <= 0 {
+		return 0, io.ErrUnexpectedEOF
+	}
+	if len(p) > r.remain {
+		p = p[:r.remain]
+	}
+	n, err := r.body.Read(p)
+	r.remain -= n
+	return n, err
+}
+
+func (r *faultTruncateBody) Close() error {
+	return r.body.Close()
+}
+
+type faultTimeoutError struct{}
+
+func (faultTimeoutError) Error() string   { return "i/o timeout" }
+func (faultTimeoutError) Timeout() bool   { return true }
+func (faultTimeoutError) Temporary() bool { return true }
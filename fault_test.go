@@ -0,0 +1,71 @@
+package gorequests_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/chyroc/gorequests"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_FaultInjector(t *testing.T) {
+	as := assert.New(t)
+
+	newFactory := func(injector *gorequests.FaultInjector) *gorequests.Factory {
+		return gorequests.NewFactory(gorequests.WithLogger(gorequests.NewDiscardLogger()), gorequests.WithFaultInjector(injector))
+	}
+
+	t.Run("status", func(t *testing.T) {
+		fac := newFactory(gorequests.NewFaultInjector().AddRule(&gorequests.FaultRule{Path: "/status/", Probability: gorequests.FaultAlways, Fault: gorequests.FaultStatus(503)}))
+		as.Equal(503, fac.New(http.MethodGet, joinHttpBinURL("/status/200")).MustResponseStatus())
+		as.Equal(200, fac.New(http.MethodGet, joinHttpBinURL("/get")).MustResponseStatus())
+	})
+
+	t.Run("connection reset", func(t *testing.T) {
+		fac := newFactory(gorequests.NewFaultInjector().Add(1, gorequests.FaultConnectionReset()))
+		_, err := fac.New(http.MethodGet, joinHttpBinURL("/get")).Text()
+		as.True(errors.Is(err, syscall.ECONNRESET))
+	})
+
+	t.Run("latency and timeout", func(t *testing.T) {
+		fac := newFactory(gorequests.NewFaultInjector().Add(1, gorequests.FaultLatency(time.Second)))
+		_, err := fac.New(http.MethodGet, joinHttpBinURL("/get")).WithTimeout(time.Millisecond * 50).Text()
+		as.NotNil(err)
+		as.Contains(err.Error(), "context deadline exceeded")
+
+		fac = newFactory(gorequests.NewFaultInjector().Add(1, gorequests.FaultTimeout()))
+		_, err = fac.New(http.MethodGet, joinHttpBinURL("/get")).WithTimeout(time.Millisecond * 50).Text()
+		as.NotNil(err)
+		as.Contains(err.Error(), "context deadline exceeded")
+	})
+
+	t.Run("truncate body", func(t *testing.T) {
+		fac := newFactory(gorequests.NewFaultInjector().Add(1, gorequests.FaultTruncateBody(10)).AddRule(&gorequests.FaultRule{Host: "127.0.0.1", Probability: gorequests.FaultAlways, Fault: gorequests.FaultCorruptHeader("Content-Type")}))
+		req := fac.New(http.MethodGet, joinHttpBinURL("/get"))
+		as.Equal("\x00\xff corrupted", req.MustResponseHeaderByKey("Content-Type"))
+		_, err := req.Text()
+		as.True(errors.Is(err, io.ErrUnexpectedEOF))
+	})
+
+	t.Run("probability", func(t *testing.T) {
+		fac := newFactory(gorequests.NewFaultInjector().WithSeed(1).Add(0.5, gorequests.FaultStatus(500)))
+		failed := 0
+		for i := 0; i < 100; i++ {
+			if fac.New(http.MethodGet, joinHttpBinURL("/get")).MustResponseStatus() == 500 {
+				failed++
+			}
+		}
+		as.True(failed > 20 && failed < 80, failed)
+
+		for _, probability := range []float64{0, -1} {
+			fac = newFactory(gorequests.NewFaultInjector().Add(probability, gorequests.FaultStatus(503)).AddRule(&gorequests.FaultRule{Fault: gorequests.FaultStatus(503)}))
+			for i := 0; i < 10; i++ {
+				as.Equal(http.StatusOK, fac.New(http.MethodGet, joinHttpBinURL("/get")).MustResponseStatus())
+			}
+		}
+	})
+}
@@ -0,0 +1,87 @@
+package gorequests_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/chyroc/gorequests"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Form(t *testing.T) {
+	as := assert.New(t)
+
+	type loginForm struct {
+		Name  string   `form:"name"`
+		Tags  []string `form:"tag"`
+		Empty string   `form:"empty,omitempty"`
+		Query string   `query:"query"`
+	}
+
+	t.Run("urlencoded", func(t *testing.T) {
+		for _, body := range []interface{}{
+			map[string]string{"name": "a", "tag": "x"},
+			url.Values{"name": {"a"}, "tag": {"x", "y"}},
+			map[string][]string{"name": {"a"}, "tag": {"x", "y"}},
+			map[string]interface{}{"name": "a", "tag": []string{"x", "y"}},
+			loginForm{Name: "a", Tags: []string{"x", "y"}, Query: "q"},
+			&loginForm{Name: "a", Tags: []string{"x", "y"}},
+		} {
+			req := gorequests.New(http.MethodPost, joinHttpBinURL("/post")).WithFormURLEncoded(body)
+			m, err := req.Get("form")
+			as.Nil(err)
+			if _, ok := body.(map[string]string); ok {
+				as.Equal(`{"name":"a","tag":"x"}`, m.Raw())
+			} else {
+				as.Equal(`{"name":"a","tag":["x","y"]}`, m.Raw(), "%T", body)
+			}
+		}
+
+		type fileForm struct {
+			File gorequests.FormFile `form:"file"`
+		}
+		_, err := gorequests.New(http.MethodPost, joinHttpBinURL("/post")).WithFormURLEncoded(fileForm{}).Text()
+		as.NotNil(err)
+	})
+
+	t.Run("urlencoded order", func(t *testing.T) {
+		type ordered struct {
+			B string `form:"b"`
+			A string `form:"a"`
+		}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.Copy(w, r.Body)
+		}))
+		defer server.Close()
+
+		text, err := gorequests.New(http.MethodPost, server.URL).WithFormURLEncoded(ordered{B: "2", A: "1 &"}).Text()
+		as.Nil(err)
+		as.Equal("b=2&a=1+%26", text)
+	})
+
+	t.Run("multipart", func(t *testing.T) {
+		type uploadForm struct {
+			Name   string               `form:"name"`
+			Tags   []string             `form:"tag"`
+			File   gorequests.FormFile  `form:"file"`
+			Avatar *gorequests.FormFile `form:"avatar"`
+			Skip   *gorequests.FormFile `form:"skip"`
+		}
+		req := gorequests.New(http.MethodPost, joinHttpBinURL("/post")).WithForm(uploadForm{
+			Name:   "a",
+			Tags:   []string{"x", "y"},
+			File:   gorequests.FormFile{Filename: "a.txt", Reader: strings.NewReader("file content")},
+			Avatar: &gorequests.FormFile{Filename: `"b".png`, Reader: strings.NewReader("png"), ContentType: "image/png"},
+		})
+		as.Contains(req.RequestHeader().Get("Content-Type"), "multipart/form-data; boundary=")
+		as.Equal(`{"name":"a","tag":["x","y"]}`, req.MustGet("form").Raw())
+		as.Equal(`{"avatar":"png","file":"file content"}`, req.MustGet("files").Raw())
+
+		req = gorequests.New(http.MethodPost, joinHttpBinURL("/post")).WithForm(map[string]string{"a": "1"})
+		as.Equal(`{"a":"1"}`, req.MustGet("form").Raw())
+	})
+}
@@ -3,14 +3,23 @@ module github.com/chyroc/gorequests
 go 1.17
 
 require (
+	github.com/andybalholm/brotli v1.0.4
 	github.com/chyroc/persistent-cookiejar v0.1.0
+	github.com/fxamacker/cbor/v2 v2.5.0
+	github.com/gorilla/websocket v1.5.3
+	github.com/klauspost/compress v1.15.15
 	github.com/stretchr/testify v1.7.0
+	github.com/vmihailenco/msgpack/v5 v5.3.5
+	golang.org/x/net v0.7.0
+	golang.org/x/text v0.13.0
+	google.golang.org/protobuf v1.33.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	golang.org/x/net v0.7.0 // indirect
+	github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
+	github.com/x448/float16 v0.8.4 // indirect
 	gopkg.in/check.v1 v1.0.0-20180628173108-788fd7840127 // indirect
-	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c // indirect
 )
@@ -0,0 +1,178 @@
+package gorequests
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// GraphQLClient send GraphQL request to endpoint, created by Factory.GraphQL
+type GraphQLClient struct {
+	factory          *Factory
+	endpoint         string
+	isPersistedQuery bool
+}
+
+// GraphQLRequest is body of GraphQL request
+type GraphQLRequest struct {
+	Query         string                 `json:"query,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Extensions    map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// GraphQLError is one error of GraphQL response errors
+type GraphQLError struct {
+	Message    string                 `json:"message"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Locations  []GraphQLLocation      `json:"locations,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// GraphQLLocation is location of GraphQL error in query
+type GraphQLLocation struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+func (r *GraphQLError) Error() string {
+	if len(r.Path) == 0 {
+		return "graphql: " + r.Message
+	}
+	path := make([]string, 0, len(r.Path))
+	for _, v := range r.Path {
+		path = append(path, fmt.Sprint(v))
+	}
+	return fmt.Sprintf("graphql: %s, path: %s", r.Message, strings.Join(path, "."))
+}
+
+// GraphQLErrors is errors of GraphQL response, can be checked by errors.As
+type GraphQLErrors []*GraphQLError
+
+func (r GraphQLErrors) Error() string {
+	msgs := make([]string, 0, len(r))
+	for _, v := range r {
+		msgs = append(msgs, v.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// As match the first *GraphQLError, errors.As of go before 1.20 does not support Unwrap() []error
+func (r GraphQLErrors) As(target interface{}) bool {
+	t, ok := target.(**GraphQLError)
+	if !ok || len(r) == 0 {
+		return false
+	}
+	*t = r[0]
+	return true
+}
+
+func (r GraphQLErrors) Unwrap() []error {
+	errs := make([]error, 0, len(r))
+	for _, v := range r {
+		errs = append(errs, v)
+	}
+	return errs
+}
+
+// GraphQL create GraphQL client of endpoint, request is created by factory
+func (r *Factory) GraphQL(endpoint string) *GraphQLClient {
+	return &GraphQLClient{factory: r, endpoint: endpoint}
+}
+
+// WithPersistedQuery set use automatic persisted query, which send sha256 hash of query first,
+// and send full query if server response PersistedQueryNotFound
+func (r *GraphQLClient) WithPersistedQuery(b bool) *GraphQLClient {
+	r.isPersistedQuery = b
+	return r
+}
+
+// Query send query with variables, and decode data of response to data
+func (r *GraphQLClient) Query(ctx context.Context, query string, variables map[string]interface{}, data interface{}) error {
+	return r.Do(ctx, &GraphQLRequest{Query: query, Variables: variables}, data)
+}
+
+// Do send GraphQL request, and decode data of response to data
+//
+// errors of response is returned as GraphQLErrors, data is still decoded if response has both data and errors.
+func (r *GraphQLClient) Do(ctx context.Context, req *GraphQLRequest, data interface{}) error {
+	if !r.isPersistedQuery || req.Query == "" {
+		return r.do(ctx, req, data)
+	}
+
+	hash := sha256.Sum256([]byte(req.Query))
+	extensions := map[string]interface{}{}
+	for k, v := range req.Extensions {
+		extensions[k] = v
+	}
+	extensions["persistedQuery"] = map[string]interface{}{
+		"version":    1,
+		"sha256Hash": hex.EncodeToString(hash[:]),
+	}
+
+	hashReq := &GraphQLRequest{Variables: req.Variables, OperationName: req.OperationName, Extensions: extensions}
+	err := r.do(ctx, hashReq, data)
+	if !isPersistedQueryNotFound(err) {
+		return err
+	}
+
+	hashReq.Query = req.Query
+	return r.do(ctx, hashReq, data)
+}
+
+func (r *GraphQLClient) do(ctx context.Context, body *GraphQLRequest, data interface{}) error {
+	req := r.factory.New(http.MethodPost, r.endpoint).WithContext(ctx).WithJSON(body).WithHeader("Accept", "application/json")
+	bs, err := req.Bytes()
+	if err != nil {
+		return err
+	}
+
+	resp := struct {
+		Data   json.RawMessage `json:"data"`
+		Errors GraphQLErrors   `json:"errors"`
+	}{}
+	if err := json.Unmarshal(bs, &resp); err != nil {
+		if req.resp.StatusCode >= 400 {
+			return fmt.Errorf("[gorequest] %s %s graphql failed, status=%d, body=%s", req.method, req.cachedurl, req.resp.StatusCode, bs)
+		}
+		return fmt.Errorf("[gorequest] %s %s unmarshal %s to graphql response failed: %w", req.method, req.cachedurl, bs, err)
+	}
+
+	if data != nil && len(resp.Data) > 0 && !bytes.Equal(resp.Data, []byte("null")) {
+		if err := req.decodeJSON(bytes.NewReader(resp.Data), data); err != nil {
+			return fmt.Errorf("[gorequest] %s %s unmarshal graphql data %s failed: %w", req.method, req.cachedurl, resp.Data, err)
+		}
+	}
+	// null error is dropped, so every error of GraphQLErrors is not nil
+	errs := resp.Errors[:0]
+	for _, v := range resp.Errors {
+		if v != nil {
+			errs = append(errs, v)
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	if req.resp.StatusCode >= 400 {
+		return fmt.Errorf("[gorequest] %s %s graphql failed, status=%d, body=%s", req.method, req.cachedurl, req.resp.StatusCode, bs)
+	}
+	return nil
+}
+
+func isPersistedQueryNotFound(err error) bool {
+	errs, ok := err.(GraphQLErrors)
+	if !ok {
+		return false
+	}
+	for _, v := range errs {
+		if v.Message == "PersistedQueryNotFound" || v.Extensions["code"] == "PERSISTED_QUERY_NOT_FOUND" {
+			return true
+		}
+	}
+	return false
+}
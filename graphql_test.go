@@ -0,0 +1,129 @@
+package gorequests_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/chyroc/gorequests"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_GraphQL(t *testing.T) {
+	as := assert.New(t)
+
+	var lock sync.Mutex
+	persisted := map[string]string{}
+	var bodies []gorequests.GraphQLRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := gorequests.GraphQLRequest{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		lock.Lock()
+		defer lock.Unlock()
+		bodies = append(bodies, body)
+
+		w.Header().Set("Content-Type", "application/json")
+		if pq, ok := body.Extensions["persistedQuery"].(map[string]interface{}); ok {
+			hash := pq["sha256Hash"].(string)
+			if body.Query == "" {
+				if body.Query = persisted[hash]; body.Query == "" {
+					_, _ = w.Write([]byte(`{"errors":[{"message":"PersistedQueryNotFound","extensions":{"code":"PERSISTED_QUERY_NOT_FOUND"}}]}`))
+					return
+				}
+			}
+			persisted[hash] = body.Query
+		}
+
+		switch body.Query {
+		case "query user($id: ID!) { user(id: $id) { name } }":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"user": map[string]interface{}{"name": body.Variables["id"]}}})
+		case "partial":
+			_, _ = w.Write([]byte(`{"data":{"user":{"name":"a"},"friends":null},"errors":[{"message":"not allowed","path":["friends",0],"locations":[{"line":1,"column":2}],"extensions":{"code":"FORBIDDEN"}},{"message":"second"}]}`))
+		case "null errors":
+			_, _ = w.Write([]byte(`{"data":{"user":{"name":"b"}},"errors":[null]}`))
+		case "mixed null errors":
+			_, _ = w.Write([]byte(`{"data":null,"errors":[null,{"message":"third"}]}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"errors":[{"message":"syntax error"}]}`))
+		}
+	}))
+	defer server.Close()
+
+	fac := gorequests.NewFactory(gorequests.WithLogger(gorequests.NewDiscardLogger()))
+	query := "query user($id: ID!) { user(id: $id) { name } }"
+
+	type userData struct {
+		User struct {
+			Name string `json:"name"`
+		} `json:"user"`
+	}
+
+	t.Run("query", func(t *testing.T) {
+		data := userData{}
+		as.Nil(fac.GraphQL(server.URL).Query(context.Background(), query, map[string]interface{}{"id": "chyroc"}, &data))
+		as.Equal("chyroc", data.User.Name)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		data := userData{}
+		err := fac.GraphQL(server.URL).Do(context.Background(), &gorequests.GraphQLRequest{Query: "partial"}, &data)
+		as.Equal("a", data.User.Name)
+
+		var errs gorequests.GraphQLErrors
+		as.True(errors.As(err, &errs))
+		as.Len(errs, 2)
+		as.Equal([]interface{}{"friends", float64(0)}, errs[0].Path)
+		as.Equal([]gorequests.GraphQLLocation{{Line: 1, Column: 2}}, errs[0].Locations)
+		as.Equal("FORBIDDEN", errs[0].Extensions["code"])
+		as.Equal("graphql: not allowed, path: friends.0; graphql: second", err.Error())
+
+		var one *gorequests.GraphQLError
+		as.True(errors.As(err, &one))
+		as.Equal("not allowed", one.Message)
+		// As is used by errors.As of go before 1.20
+		one = nil
+		as.True(errs.As(&one))
+		as.Equal("not allowed", one.Message)
+		as.False(errs.As(new(*gorequests.RPCError)))
+
+		err = fac.GraphQL(server.URL).Query(context.Background(), "bad", nil, nil)
+		as.Equal("graphql: syntax error", err.Error())
+
+		// null error is dropped
+		data = userData{}
+		as.Nil(fac.GraphQL(server.URL).Query(context.Background(), "null errors", nil, &data))
+		as.Equal("b", data.User.Name)
+
+		err = fac.GraphQL(server.URL).Query(context.Background(), "mixed null errors", nil, nil)
+		errs = nil
+		as.True(errors.As(err, &errs))
+		as.Len(errs, 1)
+		as.Equal("graphql: third", err.Error())
+	})
+
+	t.Run("persisted query", func(t *testing.T) {
+		lock.Lock()
+		bodies = nil
+		lock.Unlock()
+
+		client := fac.GraphQL(server.URL).WithPersistedQuery(true)
+		for i := 0; i < 2; i++ {
+			data := userData{}
+			as.Nil(client.Query(context.Background(), query, map[string]interface{}{"id": "pq"}, &data))
+			as.Equal("pq", data.User.Name)
+		}
+
+		lock.Lock()
+		defer lock.Unlock()
+		as.Len(bodies, 3)
+		as.Equal("", bodies[0].Query)
+		as.Equal(query, bodies[1].Query)
+		as.Equal("", bodies[2].Query)
+		as.Equal("pq", bodies[2].Variables["id"])
+	})
+}
@@ -0,0 +1,585 @@
+package gorequests
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptrace"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// HAR is the root of HTTP Archive 1.2 format, see http://www.softwareishard.com/blog/har-12-spec/
+type HAR struct {
+	Log *HARLog `json:"log"`
+}
+
+type HARLog struct {
+	Version string      `json:"version"`
+	Creator *HARCreator `json:"creator"`
+	Entries []*HAREntry `json:"entries"`
+}
+
+type HARCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type HAREntry struct {
+	StartedDateTime time.Time    `json:"startedDateTime"`
+	Time            float64      `json:"time"`
+	Request         *HARRequest  `json:"request"`
+	Response        *HARResponse `json:"response"`
+	Cache           struct{}     `json:"cache"`
+	Timings         *HARTimings  `json:"timings"`
+}
+
+type HARRequest struct {
+	Method      string          `json:"method"`
+	URL         string          `json:"url"`
+	HTTPVersion string          `json:"httpVersion"`
+	Cookies     []*HARCookie    `json:"cookies"`
+	Headers     []*HARNameValue `json:"headers"`
+	QueryString []*HARNameValue `json:"queryString"`
+	PostData    *HARPostData    `json:"postData,omitempty"`
+	HeadersSize int64           `json:"headersSize"`
+	BodySize    int64           `json:"bodySize"`
+}
+
+type HARResponse struct {
+	Status      int             `json:"status"`
+	StatusText  string          `json:"statusText"`
+	HTTPVersion string          `json:"httpVersion"`
+	Cookies     []*HARCookie    `json:"cookies"`
+	Headers     []*HARNameValue `json:"headers"`
+	Content     *HARContent     `json:"content"`
+	RedirectURL string          `json:"redirectURL"`
+	HeadersSize int64           `json:"headersSize"`
+	BodySize    int64           `json:"bodySize"`
+}
+
+type HARCookie struct {
+	Name     string     `json:"name"`
+	Value    string     `json:"value"`
+	Path     string     `json:"path,omitempty"`
+	Domain   string     `json:"domain,omitempty"`
+	Expires  *time.Time `json:"expires,omitempty"`
+	HTTPOnly bool       `json:"httpOnly,omitempty"`
+	Secure   bool       `json:"secure,omitempty"`
+}
+
+type HARNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type HARPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+	Encoding string `json:"encoding,omitempty"` // not in spec, "base64" when body is not utf-8 text
+}
+
+type HARContent struct {
+	Size      int64  `json:"size"`
+	MimeType  string `json:"mimeType"`
+	Text      string `json:"text"`
+	Encoding  string `json:"encoding,omitempty"`
+	Truncated bool   `json:"_truncated,omitempty"` // not in spec, true when body is not read to EOF
+}
+
+// HARTimings all time is millisecond, -1 means not applicable
+type HARTimings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+	SSL     float64 `json:"ssl"`
+}
+
+// LoadHAR load HAR from file
+func LoadHAR(filename string) (*HAR, error) {
+	bs, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("[gorequest] load har %s failed: %w", filename, err)
+	}
+	har := new(HAR)
+	if err := json.Unmarshal(bs, har); err != nil {
+		return nil, fmt.Errorf("[gorequest] load har %s failed: %w", filename, err)
+	}
+	if har.Log == nil {
+		har.Log = newHARLog()
+	}
+	return har, nil
+}
+
+// Save write HAR to file as json
+func (r *HAR) Save(filename string) error {
+	bs, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, bs, 0o644)
+}
+
+// HARRecorder record every request and response send by the wrapped http.RoundTripper
+type HARRecorder struct {
+	lock    sync.Mutex
+	entries []*HAREntry
+	pending []*harPending // response body not read to EOF or closed yet
+}
+
+// harPending is entry waiting for response body
+type harPending struct {
+	body  *teeBody
+	entry func(respBody []byte, complete bool) *HAREntry
+}
+
+// NewHARRecorder create HARRecorder
+func NewHARRecorder() *HARRecorder {
+	return &HARRecorder{}
+}
+
+// HAR return recorded entries as HAR
+//
+// entry whose response body is still being read is contained with body read so far, and marked as truncated.
+func (r *HARRecorder) HAR() *HAR {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	log := newHARLog()
+	log.Entries = append(log.Entries, r.entries...)
+	for _, v := range r.pending {
+		log.Entries = append(log.Entries, v.entry(v.body.bytes(), false))
+	}
+	return &HAR{Log: log}
+}
+
+// Save write recorded entries to file
+func (r *HARRecorder) Save(filename string) error {
+	return r.HAR().Save(filename)
+}
+
+// Reset clear recorded entries
+func (r *HARRecorder) Reset() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.entries = nil
+	r.pending = nil
+}
+
+// RoundTripper wrap rt, and record all traffic
+//
+// entry is recorded when response body is read to EOF or closed, so streaming response can be recorded,
+// response without body is recorded at once.
+func (r *HARRecorder) RoundTripper(rt http.RoundTripper) http.RoundTripper {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	return &harRecordRoundTripper{recorder: r, transport: rt}
+}
+
+func (r *HARRecorder) add(entry *HAREntry) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.entries = append(r.entries, entry)
+}
+
+func (r *HARRecorder) addPending(p *harPending) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.pending = append(r.pending, p)
+}
+
+// finishPending add entry of p, p removed by Reset is ignored
+func (r *HARRecorder) finishPending(p *harPending, entry *HAREntry) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for i, v := range r.pending {
+		if v == p {
+			r.pending = append(r.pending[:i], r.pending[i+1:]...)
+			r.entries = append(r.entries, entry)
+			return
+		}
+	}
+}
+
+type harRecordRoundTripper struct {
+	recorder  *HARRecorder
+	transport http.RoundTripper
+}
+
+func (lf *harRecordRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	trace := &harTrace{start: time.Now()}
+	resp, err := lf.transport.RoundTrip(req.WithContext(httptrace.WithClientTrace(req.Context(), trace.clientTrace())))
+	if err != nil {
+		return resp, err
+	}
+
+	newEntry := func(respBody []byte, complete bool) *HAREntry {
+		end := time.Now()
+		entry := &HAREntry{
+			StartedDateTime: trace.start,
+			Time:            harMillisecond(end.Sub(trace.start)),
+			Request:         newHARRequest(req, reqBody),
+			Response:        newHARResponse(resp, respBody),
+			Timings:         trace.timings(end),
+		}
+		entry.Response.Content.Truncated = !complete
+		return entry
+	}
+	if !hasResponseBody(req.Method, resp) {
+		lf.recorder.add(newEntry(nil, true))
+		return resp, nil
+	}
+
+	// entry is added when body is read to EOF or closed, so streaming response is not blocked
+	p := &harPending{entry: newEntry}
+	p.body = newTeeBody(resp.Body, func(respBody []byte, complete bool) error {
+		lf.recorder.finishPending(p, newEntry(respBody, complete))
+		return nil
+	})
+	lf.recorder.addPending(p)
+	resp.Body = p.body
+	return resp, nil
+}
+
+// hasResponseBody report whether resp may have body, response without body can be recorded before it is read
+func hasResponseBody(method string, resp *http.Response) bool {
+	if resp.Body == nil || resp.Body == http.NoBody || resp.ContentLength == 0 {
+		return false
+	}
+	return method != http.MethodHead && resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusNotModified
+}
+
+// teeBody copy response body to buffer when it is read,
+// and call done once when it is read to EOF, read failed, or closed
+//
+// complete is true only if body is read to EOF, error of done is returned by Read or Close.
+type teeBody struct {
+	body io.ReadCloser
+	lock sync.Mutex
+	buf  bytes.Buffer
+	done func(bs []byte, complete bool) error
+	once sync.Once
+}
+
+func newTeeBody(body io.ReadCloser, done func(bs []byte, complete bool) error) *teeBody {
+	return &teeBody{body: body, done: done}
+}
+
+func (r *teeBody) Read(p []byte) (int, error) {
+	n, err := r.body.Read(p)
+	r.lock.Lock()
+	r.buf.Write(p[:n])
+	r.lock.Unlock()
+	if err != nil {
+		if doneErr := r.finish(err == io.EOF); doneErr != nil {
+			return n, doneErr
+		}
+	}
+	return n, err
+}
+
+func (r *teeBody) Close() error {
+	err := r.body.Close()
+	if doneErr := r.finish(false); err == nil {
+		err = doneErr
+	}
+	return err
+}
+
+func (r *teeBody) finish(complete bool) error {
+	var err error
+	r.once.Do(func() {
+		err = r.done(r.bytes(), complete)
+	})
+	return err
+}
+
+// bytes return copy of body read so far
+func (r *teeBody) bytes() []byte {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return append([]byte(nil), r.buf.Bytes()...)
+}
+
+// harTrace collect timings of one request, callback of httptrace may be called in other goroutine
+type harTrace struct {
+	lock                                         sync.Mutex
+	start                                        time.Time
+	dnsStart, connectStart, tlsStart             time.Time
+	dns, connect, tls, blocked, send             time.Duration
+	wroteHeaders, wroteRequest, gotFirstResponse time.Time
+}
+
+func (r *harTrace) clientTrace() *httptrace.ClientTrace {
+	do := func(f func()) {
+		r.lock.Lock()
+		defer r.lock.Unlock()
+		f()
+	}
+	return &httptrace.ClientTrace{
+		GotConn:              func(httptrace.GotConnInfo) { do(func() { r.blocked = time.Since(r.start) }) },
+		DNSStart:             func(httptrace.DNSStartInfo) { do(func() { r.dnsStart = time.Now() }) },
+		DNSDone:              func(httptrace.DNSDoneInfo) { do(func() { r.dns = time.Since(r.dnsStart) }) },
+		ConnectStart:         func(string, string) { do(func() { r.connectStart = time.Now() }) },
+		ConnectDone:          func(string, string, error) { do(func() { r.connect = time.Since(r.connectStart) }) },
+		TLSHandshakeStart:    func() { do(func() { r.tlsStart = time.Now() }) },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { do(func() { r.tls = time.Since(r.tlsStart) }) },
+		WroteHeaders:         func() { do(func() { r.wroteHeaders = time.Now() }) },
+		WroteRequest:         func(httptrace.WroteRequestInfo) { do(func() { r.wroteRequest = time.Now() }) },
+		GotFirstResponseByte: func() { do(func() { r.gotFirstResponse = time.Now() }) },
+	}
+}
+
+func (r *harTrace) timings(end time.Time) *HARTimings {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	wroteHeaders, wroteRequest, gotFirstResponse := r.wroteHeaders, r.wroteRequest, r.gotFirstResponse
+	if wroteHeaders.IsZero() {
+		wroteHeaders = r.start
+	}
+	if wroteRequest.IsZero() {
+		wroteRequest = wroteHeaders
+	}
+	if gotFirstResponse.IsZero() {
+		gotFirstResponse = wroteRequest
+	}
+	return &HARTimings{
+		Blocked: harMillisecond(r.blocked - r.dns - r.connect - r.tls),
+		DNS:     harMillisecondOrNone(r.dns),
+		Connect: harMillisecondOrNone(r.connect),
+		Send:    harMillisecond(wroteRequest.Sub(wroteHeaders)),
+		Wait:    harMillisecond(gotFirstResponse.Sub(wroteRequest)),
+		Receive: harMillisecond(end.Sub(gotFirstResponse)),
+		SSL:     harMillisecondOrNone(r.tls),
+	}
+}
+
+// NewHARTransport create http.RoundTripper which response with HAR entries, instead of send request
+//
+// entry is matched by method and url, same entries are replayed in order, and the last one is reused.
+func NewHARTransport(har *HAR) http.RoundTripper {
+	return &harReplayRoundTripper{har: har, used: map[*HAREntry]bool{}}
+}
+
+type harReplayRoundTripper struct {
+	lock sync.Mutex
+	har  *HAR
+	used map[*HAREntry]bool
+}
+
+func (lf *harReplayRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		_ = req.Body.Close()
+	}
+	entry := lf.match(req)
+	if entry == nil {
+		return nil, fmt.Errorf("[gorequest] har: no entry match %s %s", req.Method, req.URL.String())
+	}
+
+	body, err := entry.Response.Content.bytes()
+	if err != nil {
+		return nil, err
+	}
+	header := http.Header{}
+	for _, v := range entry.Response.Headers {
+		header.Add(v.Name, v.Value)
+	}
+	return newStaticResponse(req, entry.Response.Status, header, body), nil
+}
+
+func (lf *harReplayRoundTripper) match(req *http.Request) *HAREntry {
+	lf.lock.Lock()
+	defer lf.lock.Unlock()
+
+	if lf.har == nil || lf.har.Log == nil {
+		return nil
+	}
+
+	var last *HAREntry
+	for _, v := range lf.har.Log.Entries {
+		if v.Request == nil || v.Response == nil {
+			continue
+		}
+		if v.Request.Method != req.Method || v.Request.URL != req.URL.String() {
+			continue
+		}
+		last = v
+		if !lf.used[v] {
+			lf.used[v] = true
+			return v
+		}
+	}
+	return last
+}
+
+func (r *HARContent) bytes() ([]byte, error) {
+	if r == nil {
+		return nil, nil
+	}
+	if r.Encoding == "base64" {
+		return base64.StdEncoding.DecodeString(r.Text)
+	}
+	return []byte(r.Text), nil
+}
+
+func newHARLog() *HARLog {
+	return &HARLog{
+		Version: "1.2",
+		Creator: &HARCreator{Name: "gorequests", Version: version},
+		Entries: []*HAREntry{},
+	}
+}
+
+func newHARRequest(req *http.Request, body []byte) *HARRequest {
+	res := &HARRequest{
+		Method:      req.Method,
+		URL:         req.URL.String(),
+		HTTPVersion: req.Proto,
+		Cookies:     []*HARCookie{},
+		Headers:     toHARNameValues(req.Header),
+		QueryString: toHARNameValues(req.URL.Query()),
+		HeadersSize: -1,
+		BodySize:    int64(len(body)),
+	}
+	if res.HTTPVersion == "" {
+		res.HTTPVersion = "HTTP/1.1"
+	}
+	for _, v := range req.Cookies() {
+		res.Cookies = append(res.Cookies, &HARCookie{Name: v.Name, Value: v.Value})
+	}
+	if body != nil {
+		text, encoding := toHARText(body)
+		res.PostData = &HARPostData{
+			MimeType: req.Header.Get("Content-Type"),
+			Text:     text,
+			Encoding: encoding,
+		}
+	}
+	return res
+}
+
+func newHARResponse(resp *http.Response, body []byte) *HARResponse {
+	text, encoding := toHARText(body)
+	res := &HARResponse{
+		Status:      resp.StatusCode,
+		StatusText:  strings.TrimSpace(strings.TrimPrefix(resp.Status, strconv.Itoa(resp.StatusCode))),
+		HTTPVersion: resp.Proto,
+		Cookies:     []*HARCookie{},
+		Headers:     toHARNameValues(resp.Header),
+		Content: &HARContent{
+			Size:     int64(len(body)),
+			MimeType: resp.Header.Get("Content-Type"),
+			Text:     text,
+			Encoding: encoding,
+		},
+		RedirectURL: resp.Header.Get("Location"),
+		HeadersSize: -1,
+		BodySize:    int64(len(body)),
+	}
+	for _, v := range resp.Cookies() {
+		cookie := &HARCookie{
+			Name:     v.Name,
+			Value:    v.Value,
+			Path:     v.Path,
+			Domain:   v.Domain,
+			HTTPOnly: v.HttpOnly,
+			Secure:   v.Secure,
+		}
+		if !v.Expires.IsZero() {
+			expires := v.Expires
+			cookie.Expires = &expires
+		}
+		res.Cookies = append(res.Cookies, cookie)
+	}
+	return res
+}
+
+func toHARNameValues(kv map[string][]string) []*HARNameValue {
+	res := []*HARNameValue{}
+	for _, k := range sortedKeys(kv) {
+		for _, v := range kv[k] {
+			res = append(res, &HARNameValue{Name: k, Value: v})
+		}
+	}
+	return res
+}
+
+func toHARText(bs []byte) (string, string) {
+	if utf8.Valid(bs) {
+		return string(bs), ""
+	}
+	return base64.StdEncoding.EncodeToString(bs), "base64"
+}
+
+func harMillisecond(d time.Duration) float64 {
+	if d < 0 {
+		return 0
+	}
+	return float64(d) / float64(time.Millisecond)
+}
+
+func harMillisecondOrNone(d time.Duration) float64 {
+	if d <= 0 {
+		return -1
+	}
+	return harMillisecond(d)
+}
+
+// newStaticResponse create response with in-memory body
+func newStaticResponse(req *http.Request, status int, header http.Header, body []byte) *http.Response {
+	if header == nil {
+		header = http.Header{}
+	}
+	header.Set("Content-Length", strconv.Itoa(len(body)))
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// readRequestBody read all body of req, and reset req body, so it can be read again
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	bs, err := ioutil.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(bs))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(bs)), nil
+	}
+	return bs, nil
+}
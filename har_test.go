@@ -0,0 +1,130 @@
+package gorequests_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/chyroc/gorequests"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_HAR(t *testing.T) {
+	as := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bs, _ := ioutil.ReadAll(r.Body)
+		w.Header().Set("X-Method", r.Method)
+		_, _ = w.Write(append([]byte(r.URL.Query().Get("a")+":"), bs...))
+	}))
+	defer server.Close()
+
+	recorder := gorequests.NewHARRecorder()
+	fac := gorequests.NewFactory(gorequests.WithLogger(gorequests.NewDiscardLogger()), gorequests.WithHARRecorder(recorder))
+
+	t.Run("record", func(t *testing.T) {
+		as.Equal("1:", fac.New(http.MethodGet, server.URL).WithQuery("a", "1").MustText())
+		as.Equal("2:body", fac.New(http.MethodPost, server.URL).WithQuery("a", "2").WithBody("body").MustText())
+
+		har := recorder.HAR()
+		as.Equal("1.2", har.Log.Version)
+		as.Len(har.Log.Entries, 2)
+
+		entry := har.Log.Entries[1]
+		as.Equal(http.MethodPost, entry.Request.Method)
+		as.Equal(server.URL+"?a=2", entry.Request.URL)
+		as.Equal("body", entry.Request.PostData.Text)
+		as.Equal(200, entry.Response.Status)
+		as.Equal("OK", entry.Response.StatusText)
+		as.Equal("2:body", entry.Response.Content.Text)
+		as.NotNil(entry.Timings)
+		as.False(entry.Response.Content.Truncated)
+	})
+
+	t.Run("unread body", func(t *testing.T) {
+		recorder := gorequests.NewHARRecorder()
+		fac := gorequests.NewFactory(gorequests.WithLogger(gorequests.NewDiscardLogger()), gorequests.WithHARRecorder(recorder))
+
+		// HEAD response has no body, and is recorded at once
+		as.Equal(http.StatusOK, fac.New(http.MethodHead, server.URL).MustResponseStatus())
+		// body of response is never read, and is flushed by HAR
+		as.Equal(http.StatusOK, fac.New(http.MethodGet, server.URL).WithQuery("a", "3").MustResponseStatus())
+
+		entries := recorder.HAR().Log.Entries
+		as.Len(entries, 2)
+		as.Equal(http.MethodHead, entries[0].Request.Method)
+		as.False(entries[0].Response.Content.Truncated)
+		as.Equal(server.URL+"?a=3", entries[1].Request.URL)
+		as.True(entries[1].Response.Content.Truncated)
+	})
+
+	t.Run("replay", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "1.har")
+		as.Nil(recorder.Save(file))
+		har, err := gorequests.LoadHAR(file)
+		as.Nil(err)
+
+		server.Close()
+		replay := gorequests.NewFactory(gorequests.WithLogger(gorequests.NewDiscardLogger()), gorequests.WithTransport(gorequests.NewHARTransport(har)))
+
+		req := replay.New(http.MethodPost, server.URL).WithQuery("a", "2").WithBody("body")
+		as.Equal("2:body", req.MustText())
+		as.Equal(http.MethodPost, req.MustResponseHeaderByKey("X-Method"))
+
+		_, err = replay.New(http.MethodGet, server.URL+"/not-found").Text()
+		as.NotNil(err)
+		as.Contains(err.Error(), "no entry match")
+	})
+
+}
+
+func Test_HARStream(t *testing.T) {
+	as := assert.New(t)
+
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("X-B", "b")
+		w.Header().Set("X-A", "a")
+		_, _ = fmt.Fprint(w, "data: 1\n\n")
+		w.(http.Flusher).Flush()
+		// keep stream open, response must not be buffered by recorder
+		select {
+		case <-done:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(done)
+
+	recorder := gorequests.NewHARRecorder()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	stop := errors.New("stop")
+	var events []string
+	err := gorequests.New(http.MethodGet, server.URL).WithContext(ctx).WithHARRecorder(recorder).WithLogger(gorequests.NewDiscardLogger()).SSE(func(event gorequests.Event) error {
+		events = append(events, event.Data)
+		return stop
+	})
+	as.Equal(stop, err)
+	as.Equal([]string{"1"}, events)
+
+	entries := recorder.HAR().Log.Entries
+	as.Len(entries, 1)
+	as.Equal("data: 1\n\n", entries[0].Response.Content.Text)
+	as.True(entries[0].Response.Content.Truncated)
+
+	var names []string
+	for _, v := range entries[0].Response.Headers {
+		names = append(names, v.Name)
+	}
+	as.True(sort.StringsAreSorted(names), names)
+}
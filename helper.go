@@ -2,15 +2,19 @@ package gorequests
 
 import (
 	"bytes"
-	"encoding/json"
+	"encoding"
 	"fmt"
 	"io"
 	"mime/multipart"
+	"net/textproto"
 	"net/url"
 	"reflect"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 )
 
 func newFileUploadRequest(params map[string]string, filekey, filename string, reader io.Reader) (string, io.Reader, error) {
@@ -37,111 +41,514 @@ func newFileUploadRequest(params map[string]string, filekey, filename string, re
 	return writer.FormDataContentType(), body, nil
 }
 
-func queryToMap(v interface{}) (map[string][]string, error) {
-	ss, err := getQueryToMapKeys(v)
+// structToMap convert struct fields with tag to k-v map, key is tag value
+func structToMap(v interface{}, tag string) (map[string][]string, error) {
+	pairs, err := structToPairs(v, tag)
 	if err != nil {
 		return nil, err
-	} else if len(ss) == 0 {
-		return map[string][]string{}, nil
 	}
 
-	vv := reflect.ValueOf(v)
-	if vv.Kind() == reflect.Ptr {
-		vv = vv.Elem()
+	vals := map[string][]string{}
+	for _, p := range pairs {
+		if p.file != nil {
+			return nil, fmt.Errorf("file field %s is only supported by multipart form", p.key)
+		}
+		vals[p.key] = append(vals[p.key], p.val)
 	}
+	return vals, nil
+}
 
-	vals := map[string][]string{}
-	for _, s := range ss {
-		vals[s.query], err = toStringList(vv.Field(s.idx))
+// FormFile is file field of struct used by WithForm, `form:"name"` tag is form key
+type FormFile struct {
+	Filename    string
+	Reader      io.Reader
+	ContentType string // default is application/octet-stream
+}
+
+var formFileType = reflect.TypeOf(FormFile{})
+
+// formToPairs convert form body to k-v pairs,
+// support: map[string]string, url.Values, map[string][]string, map[string]interface{}, struct with `form:"name"` tag
+func formToPairs(body interface{}) ([]queryPair, error) {
+	switch v := body.(type) {
+	case nil:
+		return nil, nil
+	case map[string]string:
+		pairs := make([]queryPair, 0, len(v))
+		for _, k := range sortedKeys(v) {
+			pairs = append(pairs, queryPair{key: k, val: v[k]})
+		}
+		return pairs, nil
+	case url.Values:
+		return formValuesToPairs(v), nil
+	case map[string][]string:
+		return formValuesToPairs(v), nil
+	case map[string]interface{}:
+		var pairs []queryPair
+		for _, k := range sortedKeys(v) {
+			if err := appendValuePairs(&pairs, reflect.ValueOf(v[k]), "form", k, tagOption{}); err != nil {
+				return nil, err
+			}
+		}
+		return pairs, nil
+	}
+	return structToPairs(body, "form")
+}
+
+func formValuesToPairs(v map[string][]string) []queryPair {
+	var pairs []queryPair
+	for _, k := range sortedKeys(v) {
+		for _, vv := range v[k] {
+			pairs = append(pairs, queryPair{key: k, val: vv})
+		}
+	}
+	return pairs
+}
+
+func sortedKeys(m interface{}) []string {
+	mv := reflect.ValueOf(m)
+	keys := make([]string, 0, mv.Len())
+	for _, k := range mv.MapKeys() {
+		keys = append(keys, k.String())
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// newMultipartForm encode pairs as multipart form, file of pair is written as file part
+func newMultipartForm(pairs []queryPair) (string, []byte, error) {
+	buf := new(bytes.Buffer)
+	f := multipart.NewWriter(buf)
+	for _, p := range pairs {
+		if p.file == nil {
+			if err := f.WriteField(p.key, p.val); err != nil {
+				return "", nil, err
+			}
+			continue
+		}
+
+		contentType := p.file.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, escapeQuotes(p.key), escapeQuotes(p.file.Filename)))
+		h.Set("Content-Type", contentType)
+		part, err := f.CreatePart(h)
 		if err != nil {
-			return nil, err
+			return "", nil, err
 		}
+		if p.file.Reader != nil {
+			if _, err = io.Copy(part, p.file.Reader); err != nil {
+				return "", nil, err
+			}
+		}
+	}
+	if err := f.Close(); err != nil {
+		return "", nil, err
 	}
+	return f.FormDataContentType(), buf.Bytes(), nil
+}
 
-	return vals, nil
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func escapeQuotes(s string) string {
+	return quoteEscaper.Replace(s)
 }
 
-func toStringList(v reflect.Value) ([]string, error) {
-	switch v.Kind() {
-	case reflect.String:
-		return []string{v.String()}, nil
-	case reflect.Bool:
-		if v.Bool() {
-			return []string{"true"}, nil
+// structToPairs convert struct fields with tag to k-v pairs, in order of fields
+//
+// tag format is `tag:"name,opt1,opt2"`, "-" means skip, options:
+//   - omitempty: skip zero value
+//   - unix, unixmilli: encode time.Time as unix seconds or milliseconds, default is RFC3339
+//   - comma: join slice with comma, default is repeated keys
+//   - brackets: encode slice as `name[]=a&name[]=b`
+//
+// embedded struct without tag name is flattened, nested struct and map are encoded as `name[key]`, nil pointer is skipped.
+func structToPairs(v interface{}, tag string) ([]queryPair, error) {
+	vv, ok := indirectValue(reflect.ValueOf(v))
+	if !ok {
+		return nil, nil
+	}
+	if vv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("need strcut, but got %s", vv.Kind())
+	}
+
+	var pairs []queryPair
+	if err := appendStructPairs(&pairs, vv, tag, ""); err != nil {
+		return nil, err
+	}
+	return pairs, nil
+}
+
+type queryPair struct {
+	key  string
+	val  string
+	file *FormFile // file field of multipart form
+}
+
+func appendStructPairs(pairs *[]queryPair, v reflect.Value, tag, prefix string) error {
+	for _, field := range getStructTagFields(v.Type(), tag) {
+		fv := v.Field(field.idx)
+		if field.embedded {
+			if fv, ok := indirectValue(fv); ok && fv.Kind() == reflect.Struct {
+				if err := appendStructPairs(pairs, fv, tag, prefix); err != nil {
+					return err
+				}
+			}
+			continue
 		}
-		return []string{"false"}, nil
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		return []string{strconv.FormatInt(v.Int(), 10)}, nil
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		return []string{strconv.FormatUint(v.Uint(), 10)}, nil
+
+		key := field.name
+		if prefix != "" {
+			key = prefix + "[" + field.name + "]"
+		}
+		if err := appendValuePairs(pairs, fv, tag, key, field.tagOption); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func appendValuePairs(pairs *[]queryPair, v reflect.Value, tag, key string, opt tagOption) error {
+	v, ok := indirectValue(v)
+	if !ok {
+		return nil
+	}
+	if opt.omitempty && v.IsZero() {
+		return nil
+	}
+	if v.Type() == formFileType && v.CanInterface() {
+		file := v.Interface().(FormFile)
+		*pairs = append(*pairs, queryPair{key: key, file: &file})
+		return nil
+	}
+
+	if s, ok, err := toScalarString(v, opt); err != nil {
+		return fmt.Errorf("encode %s failed: %w", key, err)
+	} else if ok {
+		*pairs = append(*pairs, queryPair{key: key, val: s})
+		return nil
+	}
+
+	switch v.Kind() {
 	case reflect.Array, reflect.Slice:
-		res := []string{}
-		for j := 0; j < v.Len(); j++ {
-			x, err := toStringList(v.Index(j))
+		if opt.omitempty && v.Len() == 0 {
+			return nil
+		}
+		list := make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			item, ok := indirectValue(v.Index(i))
+			if !ok {
+				continue
+			}
+			s, ok, err := toScalarString(item, opt)
 			if err != nil {
-				return nil, err
+				return fmt.Errorf("encode %s failed: %w", key, err)
+			} else if !ok {
+				return fmt.Errorf("invalid value: %s", item.Kind())
 			}
-			res = append(res, x...)
+			list = append(list, s)
 		}
-		return res, nil
+		switch {
+		case opt.comma:
+			*pairs = append(*pairs, queryPair{key: key, val: strings.Join(list, ",")})
+		case opt.brackets:
+			for _, s := range list {
+				*pairs = append(*pairs, queryPair{key: key + "[]", val: s})
+			}
+		default:
+			for _, s := range list {
+				*pairs = append(*pairs, queryPair{key: key, val: s})
+			}
+		}
+		return nil
+	case reflect.Struct:
+		return appendStructPairs(pairs, v, tag, key)
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("invalid map key: %s", v.Type().Key().Kind())
+		}
+		keys := make([]string, 0, v.Len())
+		for _, k := range v.MapKeys() {
+			keys = append(keys, k.String())
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if err := appendValuePairs(pairs, v.MapIndex(reflect.ValueOf(k).Convert(v.Type().Key())), tag, key+"["+k+"]", tagOption{}); err != nil {
+				return err
+			}
+		}
+		return nil
 	}
 
-	return nil, fmt.Errorf("invalid value: %s", v.Kind())
+	return fmt.Errorf("invalid value: %s", v.Kind())
+}
+
+// indirectValue dereference pointer and interface, ok is false if it is nil
+func indirectValue(v reflect.Value) (reflect.Value, bool) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return v, false
+		}
+		v = v.Elem()
+	}
+	return v, true
 }
 
-func getQueryToMapKeys(v interface{}) ([]s, error) {
-	origin := reflect.TypeOf(v)
-	v, ok := queryToMapKeys.Load(origin)
-	if ok {
-		return v.([]s), nil
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	stringerType      = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+)
+
+// toScalarString convert scalar value to string, ok is false if value is not scalar
+func toScalarString(v reflect.Value, opt tagOption) (string, bool, error) {
+	if !v.CanInterface() {
+		// unexported field, only kind is used
+		return toKindString(v)
+	}
+	if v.Type() == timeType {
+		t := v.Interface().(time.Time)
+		switch {
+		case opt.unix:
+			return strconv.FormatInt(t.Unix(), 10), true, nil
+		case opt.unixmilli:
+			return strconv.FormatInt(t.UnixMilli(), 10), true, nil
+		}
+		return t.Format(time.RFC3339), true, nil
+	}
+	if v.Type().Implements(textMarshalerType) {
+		bs, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return "", false, err
+		}
+		return string(bs), true, nil
+	}
+	if v.Type().Implements(stringerType) {
+		return v.Interface().(fmt.Stringer).String(), true, nil
 	}
+	return toKindString(v)
+}
 
-	vt := origin
-	// vv := reflect.ValueOf(v)
-	if vt.Kind() == reflect.Ptr {
-		vt = vt.Elem()
-		// vv = vv.Elem()
+func toKindString(v reflect.Value) (string, bool, error) {
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), true, nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), true, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), true, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), true, nil
+	case reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 32), true, nil
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64), true, nil
 	}
-	if vt.Kind() != reflect.Struct {
-		return nil, fmt.Errorf("need strcut, but got %s", vt.Kind())
+	return "", false, nil
+}
+
+type tagOption struct {
+	omitempty bool
+	unix      bool
+	unixmilli bool
+	comma     bool
+	brackets  bool
+}
+
+type structTagField struct {
+	tagOption
+	idx      int
+	name     string
+	embedded bool // embedded struct without tag name, which is flattened
+}
+
+func getStructTagFields(vt reflect.Type, tag string) []structTagField {
+	cacheKey := structTagKey{typ: vt, tag: tag}
+	if v, ok := structTagKeys.Load(cacheKey); ok {
+		return v.([]structTagField)
 	}
 
-	ss := []s{}
+	fields := []structTagField{}
 	for i := 0; i < vt.NumField(); i++ {
 		itemT := vt.Field(i)
-		// itemV := vv.Field(i)
 
-		queryKey := itemT.Tag.Get("query")
-		if queryKey == "" {
+		list := strings.Split(itemT.Tag.Get(tag), ",")
+		name := list[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			if itemT.Anonymous {
+				fields = append(fields, structTagField{idx: i, embedded: true})
+			}
 			continue
 		}
-		// itemV.String()
-		ss = append(ss, s{
-			idx:   i,
-			query: queryKey,
-		})
+		field := structTagField{idx: i, name: name}
+		for _, opt := range list[1:] {
+			switch strings.TrimSpace(opt) {
+			case "omitempty":
+				field.omitempty = true
+			case "unix":
+				field.unix = true
+			case "unixmilli":
+				field.unixmilli = true
+			case "comma":
+				field.comma = true
+			case "brackets":
+				field.brackets = true
+			}
+		}
+		fields = append(fields, field)
 	}
 
-	queryToMapKeys.Store(origin, ss)
-
-	return ss, nil
+	structTagKeys.Store(cacheKey, fields)
+	return fields
 }
 
 // request url
-func (r *Request) parseRequestURL() string {
-	URL, err := url.Parse(r.url)
+func (r *Request) parseRequestURL() (string, error) {
+	rawURL, err := r.fillPathParams(r.url)
 	if err != nil {
-		return r.url
+		return rawURL, err
 	}
-	q := URL.Query()
-	for k, v := range r.querys {
-		q[k] = append(q[k], v...)
+	URL, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL, nil
 	}
-	URL.RawQuery = q.Encode()
-	return URL.String()
+	if r.baseURL != "" {
+		base, err := r.fillPathParams(r.baseURL)
+		if err != nil {
+			return rawURL, err
+		}
+		baseURL, err := url.Parse(base)
+		if err != nil {
+			return rawURL, fmt.Errorf("[gorequest] %s %s parse base url %s failed: %w", r.method, rawURL, base, err)
+		}
+		URL = baseURL.ResolveReference(URL)
+	}
+	URL.RawQuery = r.encodeQuery(URL.RawQuery)
+	return URL.String(), nil
 }
 
-func toBody(body interface{}) ([]byte, io.Reader, error) {
+// encodeQuery merge raw query of url, query and default query
+func (r *Request) encodeQuery(rawQuery string) string {
+	q, _ := url.ParseQuery(rawQuery)
+	escaper := r.queryEscaper
+	if escaper == nil {
+		escaper = url.QueryEscape
+	}
+
+	if !r.isOrderedQuery {
+		for k, v := range r.querys {
+			q[k] = append(q[k], v...)
+		}
+		for k, v := range r.defaultQuerys {
+			if _, ok := q[k]; !ok {
+				q[k] = v
+			}
+		}
+		if r.queryEscaper == nil {
+			return q.Encode()
+		}
+		var pairs []queryPair
+		for _, k := range sortedKeys(q) {
+			for _, v := range q[k] {
+				pairs = append(pairs, queryPair{key: k, val: v})
+			}
+		}
+		return joinQueryPairs("", pairs, escaper)
+	}
+
+	pairs := r.queryPairs
+	for _, k := range sortedKeys(r.defaultQuerys) {
+		if _, ok := q[k]; ok {
+			continue
+		}
+		if _, ok := r.querys[k]; ok {
+			continue
+		}
+		for _, v := range r.defaultQuerys[k] {
+			pairs = append(pairs, queryPair{key: k, val: v})
+		}
+	}
+	return joinQueryPairs(rawQuery, pairs, escaper)
+}
+
+// joinQueryPairs append escaped pairs to raw query
+func joinQueryPairs(rawQuery string, pairs []queryPair, escaper func(s string) string) string {
+	buf := strings.Builder{}
+	buf.WriteString(rawQuery)
+	for _, p := range pairs {
+		if buf.Len() > 0 {
+			buf.WriteByte('&')
+		}
+		buf.WriteString(escaper(p.key))
+		buf.WriteByte('=')
+		buf.WriteString(escaper(p.val))
+	}
+	return buf.String()
+}
+
+// QueryEscapeRFC3986 escape s by RFC 3986, only unreserved characters are not escaped, and space is escaped as %20
+//
+// it is used by some signature schemes, like OAuth 1.0 and AWS Signature Version 4.
+func QueryEscapeRFC3986(s string) string {
+	const hex = "0123456789ABCDEF"
+	buf := strings.Builder{}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-' || c == '.' || c == '_' || c == '~' {
+			buf.WriteByte(c)
+			continue
+		}
+		buf.WriteByte('%')
+		buf.WriteByte(hex[c>>4])
+		buf.WriteByte(hex[c&15])
+	}
+	return buf.String()
+}
+
+var pathParamRegexp = regexp.MustCompile(`\{([^{}/?#]+)\}`)
+
+// fillPathParams replace {name} placeholders of url path with escaped path params
+func (r *Request) fillPathParams(rawURL string) (string, error) {
+	path, rest := rawURL, ""
+	if i := strings.IndexAny(rawURL, "?#"); i >= 0 {
+		path, rest = rawURL[:i], rawURL[i:]
+	}
+	if !strings.Contains(path, "{") {
+		return rawURL, nil
+	}
+
+	var missing, invalid []string
+	path = pathParamRegexp.ReplaceAllStringFunc(path, func(s string) string {
+		name := s[1 : len(s)-1]
+		val, ok := r.pathParams[name]
+		if !ok {
+			missing = append(missing, name)
+			return s
+		}
+		// empty, . and .. change path structure, and are not escaped by url.PathEscape
+		if val == "" || val == "." || val == ".." {
+			invalid = append(invalid, fmt.Sprintf("%s=%q", name, val))
+			return s
+		}
+		// ':' is escaped, or value in first segment of relative url is parsed as scheme
+		return strings.ReplaceAll(url.PathEscape(val), ":", "%3A")
+	})
+	if len(missing) > 0 {
+		return rawURL, fmt.Errorf("[gorequest] %s %s path param %s is not set", r.method, rawURL, strings.Join(missing, ", "))
+	}
+	if len(invalid) > 0 {
+		return rawURL, fmt.Errorf("[gorequest] %s %s path param %s is invalid", r.method, rawURL, strings.Join(invalid, ", "))
+	}
+	return path + rest, nil
+}
+
+// toBody convert body to []byte and io.Reader, io.Reader, []byte and string are used as-is, other type is encoded by codec
+func toBody(body interface{}, codec Codec) ([]byte, io.Reader, error) {
 	switch v := body.(type) {
 	case io.Reader:
 		return nil, v, nil
@@ -150,17 +557,21 @@ func toBody(body interface{}) ([]byte, io.Reader, error) {
 	case string:
 		return []byte(v), strings.NewReader(v), nil
 	default:
-		bs, err := json.Marshal(body)
-		if err != nil {
-			return nil, nil, err
-		}
-		return bs, bytes.NewReader(bs), nil
+		return toCodecBody(codec, body)
+	}
+}
+
+func toCodecBody(codec Codec, body interface{}) ([]byte, io.Reader, error) {
+	bs, err := codec.Marshal(body)
+	if err != nil {
+		return nil, nil, err
 	}
+	return bs, bytes.NewReader(bs), nil
 }
 
-type s struct {
-	idx   int
-	query string
+type structTagKey struct {
+	typ reflect.Type
+	tag string
 }
 
-var queryToMapKeys sync.Map
+var structTagKeys sync.Map
@@ -0,0 +1,366 @@
+// Package httpbintest implement part of httpbin(https://httpbin.org) endpoints as http.Handler, so test can run offline.
+//
+// supported endpoints:
+//
+//	/ip
+//	/user-agent
+//	/headers
+//	/get, /post, /put, /patch, /delete, /anything
+//	/status/{code}
+//	/redirect/{n}
+//	/cookies
+//	/cookies/set?k=v
+//	/delay/{n}
+//	/gzip
+//	/stream/{n}
+//	/basic-auth/{user}/{passwd}
+//	/bytes/{n}?seed={seed}
+package httpbintest
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NewHandler create httpbin compatible http.Handler
+func NewHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ip", handleIP)
+	mux.HandleFunc("/user-agent", handleUserAgent)
+	mux.HandleFunc("/headers", handleHeaders)
+	mux.HandleFunc("/get", methodHandler(handleGet, http.MethodGet, http.MethodHead))
+	mux.HandleFunc("/post", methodHandler(handleBody, http.MethodPost))
+	mux.HandleFunc("/put", methodHandler(handleBody, http.MethodPut))
+	mux.HandleFunc("/patch", methodHandler(handleBody, http.MethodPatch))
+	mux.HandleFunc("/delete", methodHandler(handleBody, http.MethodDelete))
+	mux.HandleFunc("/anything", handleBody)
+	mux.HandleFunc("/anything/", handleBody)
+	mux.HandleFunc("/status/", handleStatus)
+	mux.HandleFunc("/redirect/", handleRedirect)
+	mux.HandleFunc("/cookies", handleCookies)
+	mux.HandleFunc("/cookies/set", handleCookiesSet)
+	mux.HandleFunc("/delay/", handleDelay)
+	mux.HandleFunc("/gzip", handleGzip)
+	mux.HandleFunc("/stream/", handleStream)
+	mux.HandleFunc("/basic-auth/", handleBasicAuth)
+	mux.HandleFunc("/bytes/", handleBytes)
+	return mux
+}
+
+// NewServer start httptest.Server with httpbin compatible handler, caller should close it
+func NewServer() *httptest.Server {
+	return httptest.NewServer(NewHandler())
+}
+
+func handleIP(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{"origin": origin(r)})
+}
+
+func handleUserAgent(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{"user-agent": r.UserAgent()})
+}
+
+func handleHeaders(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{"headers": headers(r)})
+}
+
+func handleGet(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, getResponse(r))
+}
+
+func handleBody(w http.ResponseWriter, r *http.Request) {
+	resp := getResponse(r)
+	resp["method"] = r.Method
+	resp["data"] = ""
+	resp["form"] = map[string]interface{}{}
+	resp["files"] = map[string]interface{}{}
+	resp["json"] = nil
+
+	contentType := r.Header.Get("Content-Type")
+	switch {
+	case strings.HasPrefix(contentType, "multipart/form-data"):
+		if err := r.ParseMultipartForm(32 << 20); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+			return
+		}
+		resp["form"] = flatten(r.MultipartForm.Value)
+		files := map[string][]string{}
+		for k, fs := range r.MultipartForm.File {
+			for _, fh := range fs {
+				f, err := fh.Open()
+				if err != nil {
+					writeJSON(w, http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+					return
+				}
+				bs, err := ioutil.ReadAll(f)
+				_ = f.Close()
+				if err != nil {
+					writeJSON(w, http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+					return
+				}
+				files[k] = append(files[k], string(bs))
+			}
+		}
+		resp["files"] = flatten(files)
+	case strings.HasPrefix(contentType, "application/x-www-form-urlencoded"):
+		if err := r.ParseForm(); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+			return
+		}
+		resp["form"] = flatten(r.PostForm)
+	default:
+		bs, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+			return
+		}
+		resp["data"] = string(bs)
+		var v interface{}
+		if json.Unmarshal(bs, &v) == nil {
+			resp["json"] = v
+		}
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func handleStatus(w http.ResponseWriter, r *http.Request) {
+	code, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/status/"))
+	if err != nil || code < 100 || code > 999 {
+		http.Error(w, "invalid status code", http.StatusBadRequest)
+		return
+	}
+	switch code {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		w.Header().Set("Location", "/redirect/1")
+	case http.StatusUnauthorized:
+		w.Header().Set("WWW-Authenticate", `Basic realm="Fake Realm"`)
+	}
+	w.WriteHeader(code)
+}
+
+func handleRedirect(w http.ResponseWriter, r *http.Request) {
+	n, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/redirect/"))
+	if err != nil || n < 1 {
+		http.Error(w, "invalid redirect count", http.StatusBadRequest)
+		return
+	}
+	location := "/get"
+	if n > 1 {
+		location = "/redirect/" + strconv.Itoa(n-1)
+	}
+	http.Redirect(w, r, location, http.StatusFound)
+}
+
+func handleCookies(w http.ResponseWriter, r *http.Request) {
+	cookies := map[string]string{}
+	for _, v := range r.Cookies() {
+		cookies[v.Name] = v.Value
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"cookies": cookies})
+}
+
+func handleCookiesSet(w http.ResponseWriter, r *http.Request) {
+	for k, vs := range r.URL.Query() {
+		for _, v := range vs {
+			http.SetCookie(w, &http.Cookie{Name: k, Value: v, Path: "/"})
+		}
+	}
+	http.Redirect(w, r, "/cookies", http.StatusFound)
+}
+
+func handleDelay(w http.ResponseWriter, r *http.Request) {
+	n, err := strconv.ParseFloat(strings.TrimPrefix(r.URL.Path, "/delay/"), 64)
+	if err != nil || n < 0 {
+		http.Error(w, "invalid delay", http.StatusBadRequest)
+		return
+	}
+	if n > 10 {
+		n = 10
+	}
+
+	timer := time.NewTimer(time.Duration(n * float64(time.Second)))
+	defer timer.Stop()
+	select {
+	case <-r.Context().Done():
+		return
+	case <-timer.C:
+	}
+
+	resp := getResponse(r)
+	resp["data"] = ""
+	resp["form"] = map[string]interface{}{}
+	resp["files"] = map[string]interface{}{}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func handleGzip(w http.ResponseWriter, r *http.Request) {
+	bs, err := json.Marshal(map[string]interface{}{
+		"gzipped": true,
+		"headers": headers(r),
+		"method":  r.Method,
+		"origin":  origin(r),
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Encoding", "gzip")
+	gw := gzip.NewWriter(w)
+	_, _ = gw.Write(bs)
+	_ = gw.Close()
+}
+
+func handleStream(w http.ResponseWriter, r *http.Request) {
+	n, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/stream/"))
+	if err != nil || n < 0 {
+		http.Error(w, "invalid stream count", http.StatusBadRequest)
+		return
+	}
+	if n > 100 {
+		n = 100
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	flusher, _ := w.(http.Flusher)
+	for i := 0; i < n; i++ {
+		resp := getResponse(r)
+		resp["id"] = i
+		bs, _ := json.Marshal(resp)
+		if _, err := w.Write(append(bs, '\n')); err != nil {
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+}
+
+func handleBasicAuth(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/basic-auth/"), "/")
+	if len(parts) != 2 {
+		http.Error(w, "invalid basic auth path", http.StatusBadRequest)
+		return
+	}
+
+	user, passwd, ok := r.BasicAuth()
+	if !ok || user != parts[0] || passwd != parts[1] {
+		w.Header().Set("WWW-Authenticate", `Basic realm="Fake Realm"`)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"authenticated": true, "user": user})
+}
+
+func handleBytes(w http.ResponseWriter, r *http.Request) {
+	n, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/bytes/"))
+	if err != nil || n < 0 {
+		http.Error(w, "invalid bytes count", http.StatusBadRequest)
+		return
+	}
+	if n > 100*1024 {
+		n = 100 * 1024
+	}
+
+	seed := time.Now().UnixNano()
+	if s := r.URL.Query().Get("seed"); s != "" {
+		if seed, err = strconv.ParseInt(s, 10, 64); err != nil {
+			http.Error(w, "invalid seed", http.StatusBadRequest)
+			return
+		}
+	}
+
+	bs := make([]byte, n)
+	rand.New(rand.NewSource(seed)).Read(bs)
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Length", strconv.Itoa(n))
+	_, _ = w.Write(bs)
+}
+
+func methodHandler(f http.HandlerFunc, methods ...string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		for _, v := range methods {
+			if r.Method == v {
+				f(w, r)
+				return
+			}
+		}
+		w.Header().Set("Allow", strings.Join(methods, ", "))
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
+
+func getResponse(r *http.Request) map[string]interface{} {
+	return map[string]interface{}{
+		"args":    flatten(r.URL.Query()),
+		"headers": headers(r),
+		"origin":  origin(r),
+		"url":     fullURL(r),
+	}
+}
+
+// headers return request headers, multi values of same key are joined with ","
+func headers(r *http.Request) map[string]string {
+	res := map[string]string{}
+	for k, v := range r.Header {
+		res[k] = strings.Join(v, ",")
+	}
+	if r.Host != "" {
+		res["Host"] = r.Host
+	}
+	return res
+}
+
+// flatten return single value as string, multi values as []string
+func flatten(kv map[string][]string) map[string]interface{} {
+	res := map[string]interface{}{}
+	for k, v := range kv {
+		if len(v) == 1 {
+			res[k] = v[0]
+		} else {
+			res[k] = v
+		}
+	}
+	return res
+}
+
+func origin(r *http.Request) string {
+	if v := r.Header.Get("X-Forwarded-For"); v != "" {
+		return v
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func fullURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s%s", scheme, r.Host, r.URL.RequestURI())
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	bs, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(append(bs, '\n'))
+}
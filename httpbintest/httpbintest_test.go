@@ -0,0 +1,93 @@
+package httpbintest_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/cookiejar"
+	"testing"
+
+	"github.com/chyroc/gorequests/httpbintest"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Handler(t *testing.T) {
+	as := assert.New(t)
+
+	server := httpbintest.NewServer()
+	defer server.Close()
+
+	getJSON := func(c *http.Client, path string) (int, map[string]interface{}) {
+		resp, err := c.Get(server.URL + path)
+		as.Nil(err)
+		defer resp.Body.Close()
+		m := map[string]interface{}{}
+		bs, err := ioutil.ReadAll(resp.Body)
+		as.Nil(err)
+		if len(bs) > 0 {
+			as.Nil(json.Unmarshal(bs, &m))
+		}
+		return resp.StatusCode, m
+	}
+
+	t.Run("/redirect", func(t *testing.T) {
+		status, m := getJSON(http.DefaultClient, "/redirect/3?a=1")
+		as.Equal(http.StatusOK, status)
+		as.Equal(server.URL+"/get", m["url"])
+	})
+
+	t.Run("/cookies", func(t *testing.T) {
+		jar, _ := cookiejar.New(nil)
+		status, m := getJSON(&http.Client{Jar: jar}, "/cookies/set?a=b")
+		as.Equal(http.StatusOK, status)
+		as.Equal(map[string]interface{}{"a": "b"}, m["cookies"])
+	})
+
+	t.Run("/gzip", func(t *testing.T) {
+		status, m := getJSON(http.DefaultClient, "/gzip")
+		as.Equal(http.StatusOK, status)
+		as.Equal(true, m["gzipped"])
+	})
+
+	t.Run("/basic-auth", func(t *testing.T) {
+		status, _ := getJSON(http.DefaultClient, "/basic-auth/u/p")
+		as.Equal(http.StatusUnauthorized, status)
+
+		req, _ := http.NewRequest(http.MethodGet, server.URL+"/basic-auth/u/p", nil)
+		req.SetBasicAuth("u", "p")
+		resp, err := http.DefaultClient.Do(req)
+		as.Nil(err)
+		resp.Body.Close()
+		as.Equal(http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("/stream", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "/stream/3")
+		as.Nil(err)
+		defer resp.Body.Close()
+		lines := 0
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			m := map[string]interface{}{}
+			as.Nil(json.Unmarshal(scanner.Bytes(), &m))
+			as.Equal(float64(lines), m["id"])
+			lines++
+		}
+		as.Equal(3, lines)
+	})
+
+	t.Run("/bytes", func(t *testing.T) {
+		read := func() []byte {
+			resp, err := http.Get(server.URL + "/bytes/16?seed=1")
+			as.Nil(err)
+			defer resp.Body.Close()
+			bs, err := ioutil.ReadAll(resp.Body)
+			as.Nil(err)
+			return bs
+		}
+		bs := read()
+		as.Len(bs, 16)
+		as.Equal(bs, read())
+	})
+}
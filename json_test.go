@@ -0,0 +1,86 @@
+package gorequests_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chyroc/gorequests"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_JSONDecodeOption(t *testing.T) {
+	as := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":1234567890123456789,"name":"chyroc"}`))
+	}))
+	defer server.Close()
+
+	type user struct {
+		ID int64 `json:"id"`
+	}
+
+	t.Run("default", func(t *testing.T) {
+		m, err := gorequests.New(http.MethodGet, server.URL).Map()
+		as.Nil(err)
+		as.Equal(float64(1234567890123456789), m["id"])
+
+		resp := user{}
+		as.Nil(gorequests.New(http.MethodGet, server.URL).Unmarshal(&resp))
+		as.Equal(int64(1234567890123456789), resp.ID)
+	})
+
+	t.Run("use number", func(t *testing.T) {
+		m, err := gorequests.New(http.MethodGet, server.URL).WithJSONUseNumber(true).Map()
+		as.Nil(err)
+		as.Equal(json.Number("1234567890123456789"), m["id"])
+	})
+
+	t.Run("disallow unknown fields", func(t *testing.T) {
+		resp := user{}
+		err := gorequests.New(http.MethodGet, server.URL).WithJSONDisallowUnknownFields(true).Unmarshal(&resp)
+		as.NotNil(err)
+		as.Contains(err.Error(), `unknown field "name"`)
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		fac := gorequests.NewFactory(gorequests.WithStreamUnmarshal(true), gorequests.WithJSONUseNumber(true))
+
+		m, err := fac.New(http.MethodGet, server.URL).Map()
+		as.Nil(err)
+		as.Equal(json.Number("1234567890123456789"), m["id"])
+
+		resp := user{}
+		err = fac.New(http.MethodGet, server.URL).WithJSONDisallowUnknownFields(true).Unmarshal(&resp)
+		as.NotNil(err)
+		as.Contains(err.Error(), "unmarshal stream")
+
+		// body is not buffered after stream
+		req := fac.New(http.MethodGet, server.URL)
+		as.Nil(req.Unmarshal(&map[string]interface{}{}))
+		_, err = req.Text()
+		as.NotNil(err)
+		as.Contains(err.Error(), "already read by stream")
+		as.NotNil(req.Unmarshal(&map[string]interface{}{}))
+		as.Equal(http.StatusOK, req.MustResponseStatus())
+	})
+
+	t.Run("trailing data", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"id":1} trailing garbage`))
+		}))
+		defer server.Close()
+
+		_, err := gorequests.New(http.MethodGet, server.URL).WithJSONUseNumber(true).Map()
+		as.NotNil(err)
+		as.Contains(err.Error(), "invalid data after top-level value")
+
+		err = gorequests.New(http.MethodGet, server.URL).WithStreamUnmarshal(true).Unmarshal(&user{})
+		as.NotNil(err)
+		as.Contains(err.Error(), "invalid data after top-level value")
+	})
+}
@@ -0,0 +1,225 @@
+package gorequests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Get extract value from json response by path, like gjson
+//
+// path is separated by dot, and:
+//   - `a.b` get field b of object a, `\.` escape dot in key
+//   - `a.0` get the first element of array a
+//   - `a.#` get length of array a
+//   - `a.#.id` get field id of every element of array a, as array
+//
+// not exist path return Value which Exists() is false, not error.
+func (r *Request) Get(path string) (Value, error) {
+	bs, err := r.Bytes()
+	if err != nil {
+		return Value{}, err
+	}
+
+	var val interface{}
+	d := json.NewDecoder(bytes.NewReader(bs))
+	d.UseNumber()
+	if err := d.Decode(&val); err != nil {
+		return Value{}, fmt.Errorf("[gorequest] %s %s unmarshal %s to json failed: %w", r.method, r.cachedurl, bs, err)
+	}
+	return getJSONPath(val, splitJSONPath(path)), nil
+}
+
+func (r *Request) MustGet(path string) Value {
+	val, _ := r.Get(path)
+	return val
+}
+
+// Value is json value returned by Get
+type Value struct {
+	val    interface{} // nil, bool, json.Number, string, []interface{}, map[string]interface{}
+	exists bool
+}
+
+// Exists return whether path exists in json
+func (r Value) Exists() bool {
+	return r.exists
+}
+
+// Value return raw go value: nil, bool, json.Number, string, []interface{}, map[string]interface{}
+func (r Value) Value() interface{} {
+	return r.val
+}
+
+// Raw return json encoded value, empty string if not exists
+func (r Value) Raw() string {
+	if !r.exists {
+		return ""
+	}
+	buf := new(bytes.Buffer)
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(r.val); err != nil {
+		return ""
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// String return string value, number and bool is formatted, array and object is returned as json
+func (r Value) String() string {
+	switch v := r.val.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case json.Number:
+		return v.String()
+	default:
+		return r.Raw()
+	}
+}
+
+// Int return int value, string is parsed, true is 1
+func (r Value) Int() int64 {
+	switch v := r.val.(type) {
+	case bool:
+		if v {
+			return 1
+		}
+		return 0
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return int64(f)
+	case string:
+		if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return i
+		}
+		f, _ := strconv.ParseFloat(v, 64)
+		return int64(f)
+	}
+	return 0
+}
+
+// Float return float value, string is parsed, true is 1
+func (r Value) Float() float64 {
+	switch v := r.val.(type) {
+	case bool:
+		if v {
+			return 1
+		}
+		return 0
+	case json.Number:
+		f, _ := v.Float64()
+		return f
+	case string:
+		f, _ := strconv.ParseFloat(v, 64)
+		return f
+	}
+	return 0
+}
+
+// Bool return bool value, string is parsed, non-zero number is true
+func (r Value) Bool() bool {
+	switch v := r.val.(type) {
+	case bool:
+		return v
+	case json.Number:
+		f, _ := v.Float64()
+		return f != 0
+	case string:
+		b, _ := strconv.ParseBool(v)
+		return b
+	}
+	return false
+}
+
+// Array return elements of array, nil if not array
+func (r Value) Array() []Value {
+	list, ok := r.val.([]interface{})
+	if !ok {
+		return nil
+	}
+	res := make([]Value, 0, len(list))
+	for _, v := range list {
+		res = append(res, Value{val: v, exists: true})
+	}
+	return res
+}
+
+// Map return fields of object, nil if not object
+func (r Value) Map() map[string]Value {
+	m, ok := r.val.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	res := make(map[string]Value, len(m))
+	for k, v := range m {
+		res[k] = Value{val: v, exists: true}
+	}
+	return res
+}
+
+func getJSONPath(val interface{}, path []string) Value {
+	for i, key := range path {
+		switch v := val.(type) {
+		case map[string]interface{}:
+			item, ok := v[key]
+			if !ok {
+				return Value{}
+			}
+			val = item
+		case []interface{}:
+			if key == "#" {
+				if i == len(path)-1 {
+					return Value{val: json.Number(strconv.Itoa(len(v))), exists: true}
+				}
+				res := make([]interface{}, 0, len(v))
+				for _, item := range v {
+					if itemVal := getJSONPath(item, path[i+1:]); itemVal.exists {
+						res = append(res, itemVal.val)
+					}
+				}
+				return Value{val: res, exists: true}
+			}
+			idx, err := strconv.Atoi(key)
+			if err != nil || idx < 0 || idx >= len(v) {
+				return Value{}
+			}
+			val = v[idx]
+		default:
+			return Value{}
+		}
+	}
+	return Value{val: val, exists: true}
+}
+
+// splitJSONPath split path by dot, `\.` is escaped dot
+func splitJSONPath(path string) []string {
+	if path == "" {
+		return nil
+	}
+	var (
+		res []string
+		buf strings.Builder
+	)
+	for i := 0; i < len(path); i++ {
+		switch {
+		case path[i] == '\\' && i+1 < len(path):
+			i++
+			buf.WriteByte(path[i])
+		case path[i] == '.':
+			res = append(res, buf.String())
+			buf.Reset()
+		default:
+			buf.WriteByte(path[i])
+		}
+	}
+	return append(res, buf.String())
+}
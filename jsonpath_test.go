@@ -0,0 +1,68 @@
+package gorequests_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chyroc/gorequests"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Get(t *testing.T) {
+	as := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":{"total":"2","ok":true,"items":[{"id":1234567890123456789,"name":"a<b"},{"id":2,"name":"b","tags":["x"]}],"a.b":1.5}}`))
+	}))
+	defer server.Close()
+
+	get := func(path string) gorequests.Value {
+		val, err := gorequests.New(http.MethodGet, server.URL).Get(path)
+		as.Nil(err)
+		return val
+	}
+
+	t.Run("object", func(t *testing.T) {
+		as.Equal(int64(2), get("data.total").Int())
+		as.True(get("data.ok").Bool())
+		as.Equal(1.5, get(`data.a\.b`).Float())
+		as.Equal("a<b", get("data.items.0.name").String())
+		as.Equal(`"a<b"`, get("data.items.0.name").Raw())
+		as.Equal(int64(1234567890123456789), get("data.items.0.id").Int())
+		as.Len(get("data").Map(), 4)
+	})
+
+	t.Run("array", func(t *testing.T) {
+		as.Equal(int64(2), get("data.items.#").Int())
+		as.Equal("[1234567890123456789,2]", get("data.items.#.id").Raw())
+		as.Equal(`[["x"]]`, get("data.items.#.tags").Raw())
+
+		names := []string{}
+		for _, v := range get("data.items.#.name").Array() {
+			names = append(names, v.String())
+		}
+		as.Equal([]string{"a<b", "b"}, names)
+	})
+
+	t.Run("not exist", func(t *testing.T) {
+		for _, path := range []string{"x", "data.items.2", "data.items.x", "data.total.x", "data.items.-1"} {
+			val := get(path)
+			as.False(val.Exists(), path)
+			as.Equal("", val.String())
+			as.Equal(int64(0), val.Int())
+			as.Nil(val.Array())
+		}
+	})
+
+	t.Run("httpbin", func(t *testing.T) {
+		val := gorequests.New(http.MethodGet, joinHttpBinURL("/get")).WithQuery("a", "1").MustGet("args.a")
+		as.True(val.Exists())
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		_, err := gorequests.New(http.MethodGet, joinHttpBinURL("/status/200")).Get("a")
+		as.NotNil(err)
+	})
+}
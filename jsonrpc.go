@@ -0,0 +1,171 @@
+package gorequests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+)
+
+// JSONRPCClient send JSON-RPC 2.0 request to endpoint over http, created by Factory.JSONRPC
+type JSONRPCClient struct {
+	factory  *Factory
+	endpoint string
+	id       uint64
+}
+
+// RPCError is error object of JSON-RPC response
+type RPCError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (r *RPCError) Error() string {
+	if len(r.Data) == 0 {
+		return fmt.Sprintf("jsonrpc: code=%d, message=%s", r.Code, r.Message)
+	}
+	return fmt.Sprintf("jsonrpc: code=%d, message=%s, data=%s", r.Code, r.Message, r.Data)
+}
+
+// RPCCall is one call of JSONRPCClient.Batch
+//
+// Result is decoded from response result, Error is set if response is error.
+// Notify call has no response, Result and Error are not set.
+type RPCCall struct {
+	Method string
+	Params interface{}
+	Result interface{}
+	Notify bool
+	Error  error
+}
+
+type jsonrpcRequest struct {
+	JSONRPC string      `json:"jsonrpc"`
+	ID      *uint64     `json:"id,omitempty"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
+type jsonrpcResponse struct {
+	ID     json.RawMessage `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *RPCError       `json:"error"`
+}
+
+// JSONRPC create JSON-RPC 2.0 client of endpoint, request is created by factory
+func (r *Factory) JSONRPC(endpoint string) *JSONRPCClient {
+	return &JSONRPCClient{factory: r, endpoint: endpoint}
+}
+
+// Call call method with params, and decode result to result, error object is returned as *RPCError
+//
+// params should be array or object, nil means no params. result can be nil.
+func (r *JSONRPCClient) Call(ctx context.Context, method string, params, result interface{}) error {
+	id := r.nextID()
+	req, bs, err := r.send(ctx, &jsonrpcRequest{JSONRPC: "2.0", ID: &id, Method: method, Params: params})
+	if err != nil {
+		return err
+	}
+
+	resp := new(jsonrpcResponse)
+	if err := json.Unmarshal(bs, resp); err != nil {
+		return fmt.Errorf("[gorequest] %s %s unmarshal %s to jsonrpc response failed: %w", req.method, req.cachedurl, bs, err)
+	}
+	// id is null if server can not parse request
+	if respID := string(bytes.Trim(resp.ID, `"`)); respID != strconv.FormatUint(id, 10) && !(respID == "null" && resp.Error != nil) {
+		return fmt.Errorf("[gorequest] %s %s jsonrpc response id %s mismatch request id %d", req.method, req.cachedurl, resp.ID, id)
+	}
+	return req.decodeRPCResult(resp, result)
+}
+
+// Notify send notification, which has no id and no response
+func (r *JSONRPCClient) Notify(ctx context.Context, method string, params interface{}) error {
+	_, _, err := r.send(ctx, &jsonrpcRequest{JSONRPC: "2.0", Method: method, Params: params})
+	return err
+}
+
+// Batch send calls in one batch request, response is correlated to call by id
+//
+// error of every call is set to RPCCall.Error, returned error is only for the whole batch.
+func (r *JSONRPCClient) Batch(ctx context.Context, calls []*RPCCall) error {
+	if len(calls) == 0 {
+		return nil
+	}
+
+	body := make([]*jsonrpcRequest, 0, len(calls))
+	callMap := map[string]*RPCCall{}
+	for _, call := range calls {
+		call.Error = nil
+		v := &jsonrpcRequest{JSONRPC: "2.0", Method: call.Method, Params: call.Params}
+		if !call.Notify {
+			id := r.nextID()
+			v.ID = &id
+			callMap[strconv.FormatUint(id, 10)] = call
+		}
+		body = append(body, v)
+	}
+
+	req, bs, err := r.send(ctx, body)
+	if err != nil || len(callMap) == 0 {
+		return err
+	}
+
+	var resps []*jsonrpcResponse
+	if err := json.Unmarshal(bs, &resps); err != nil {
+		// server return single error object, when batch request is invalid
+		resp := new(jsonrpcResponse)
+		if json.Unmarshal(bs, resp) == nil && resp.Error != nil {
+			return resp.Error
+		}
+		return fmt.Errorf("[gorequest] %s %s unmarshal %s to jsonrpc batch response failed: %w", req.method, req.cachedurl, bs, err)
+	}
+	for _, resp := range resps {
+		if resp == nil {
+			continue
+		}
+		call, ok := callMap[string(bytes.Trim(resp.ID, `"`))]
+		if !ok {
+			continue
+		}
+		delete(callMap, string(bytes.Trim(resp.ID, `"`)))
+		call.Error = req.decodeRPCResult(resp, call.Result)
+	}
+	for id, call := range callMap {
+		call.Error = fmt.Errorf("[gorequest] %s %s jsonrpc batch response of id %s is missing", req.method, req.cachedurl, id)
+	}
+	return nil
+}
+
+func (r *JSONRPCClient) send(ctx context.Context, body interface{}) (*Request, []byte, error) {
+	req := r.factory.New(http.MethodPost, r.endpoint).WithContext(ctx).WithJSON(body)
+	bs, err := req.Bytes()
+	if err != nil {
+		return req, nil, err
+	}
+	// notification may get 204 or 200 with empty body
+	if req.resp.StatusCode >= 400 && !json.Valid(bs) {
+		return req, nil, fmt.Errorf("[gorequest] %s %s jsonrpc failed, status=%d, body=%s", req.method, req.cachedurl, req.resp.StatusCode, bs)
+	}
+	return req, bs, nil
+}
+
+func (r *JSONRPCClient) nextID() uint64 {
+	return atomic.AddUint64(&r.id, 1)
+}
+
+func (r *Request) decodeRPCResult(resp *jsonrpcResponse, result interface{}) error {
+	if resp.Error != nil {
+		return resp.Error
+	}
+	if result == nil || len(resp.Result) == 0 {
+		return nil
+	}
+	if err := r.decodeJSON(bytes.NewReader(resp.Result), result); err != nil {
+		return fmt.Errorf("[gorequest] %s %s unmarshal jsonrpc result %s failed: %w", r.method, r.cachedurl, resp.Result, err)
+	}
+	return nil
+}
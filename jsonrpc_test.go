@@ -0,0 +1,136 @@
+package gorequests_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chyroc/gorequests"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_JSONRPC(t *testing.T) {
+	as := assert.New(t)
+
+	type rpcReq struct {
+		JSONRPC string            `json:"jsonrpc"`
+		ID      json.RawMessage   `json:"id"`
+		Method  string            `json:"method"`
+		Params  []json.RawMessage `json:"params"`
+	}
+	handle := func(req rpcReq) map[string]interface{} {
+		resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+		switch req.Method {
+		case "add":
+			var a, b int
+			_ = json.Unmarshal(req.Params[0], &a)
+			_ = json.Unmarshal(req.Params[1], &b)
+			resp["result"] = a + b
+		case "wrong_id":
+			resp["id"] = 0
+			resp["result"] = 0
+		default:
+			resp["error"] = map[string]interface{}{"code": -32601, "message": "Method not found", "data": req.Method}
+		}
+		return resp
+	}
+
+	var notified []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var raw json.RawMessage
+		_ = json.NewDecoder(r.Body).Decode(&raw)
+		w.Header().Set("Content-Type", "application/json")
+
+		if raw[0] == '[' {
+			var reqs []rpcReq
+			_ = json.Unmarshal(raw, &reqs)
+			var resps []map[string]interface{}
+			// response in reverse order
+			for i := len(reqs) - 1; i >= 0; i-- {
+				if reqs[i].ID == nil {
+					notified = append(notified, reqs[i].Method)
+					continue
+				}
+				if reqs[i].Method == "null" {
+					resps = append(resps, nil)
+					continue
+				}
+				resps = append(resps, handle(reqs[i]))
+			}
+			if len(resps) == 0 {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(resps)
+			return
+		}
+
+		req := rpcReq{}
+		_ = json.Unmarshal(raw, &req)
+		if req.ID == nil {
+			notified = append(notified, req.Method)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(handle(req))
+	}))
+	defer server.Close()
+
+	client := gorequests.NewFactory(gorequests.WithLogger(gorequests.NewDiscardLogger())).JSONRPC(server.URL)
+	ctx := context.Background()
+
+	t.Run("call", func(t *testing.T) {
+		var sum int
+		as.Nil(client.Call(ctx, "add", []int{1, 2}, &sum))
+		as.Equal(3, sum)
+
+		err := client.Call(ctx, "sub", []int{1, 2}, &sum)
+		var rpcErr *gorequests.RPCError
+		as.True(errors.As(err, &rpcErr))
+		as.Equal(-32601, rpcErr.Code)
+		as.Equal("Method not found", rpcErr.Message)
+		as.Equal(`"sub"`, string(rpcErr.Data))
+
+		err = client.Call(ctx, "wrong_id", nil, &sum)
+		as.NotNil(err)
+		as.Contains(err.Error(), "mismatch request id")
+	})
+
+	t.Run("notify", func(t *testing.T) {
+		notified = nil
+		as.Nil(client.Notify(ctx, "log", []string{"hi"}))
+		as.Equal([]string{"log"}, notified)
+	})
+
+	t.Run("batch", func(t *testing.T) {
+		notified = nil
+		var a, b int
+		calls := []*gorequests.RPCCall{
+			{Method: "add", Params: []int{1, 2}, Result: &a},
+			{Method: "log", Notify: true},
+			{Method: "add", Params: []int{3, 4}, Result: &b},
+			{Method: "sub", Params: []int{3, 4}},
+		}
+		as.Nil(client.Batch(ctx, calls))
+		as.Equal(3, a)
+		as.Equal(7, b)
+		as.Nil(calls[0].Error)
+		as.Nil(calls[1].Error)
+		as.Nil(calls[2].Error)
+		as.NotNil(calls[3].Error)
+		as.Equal([]string{"log"}, notified)
+
+		as.Nil(client.Batch(ctx, []*gorequests.RPCCall{{Method: "log", Notify: true}}))
+
+		// null response is skipped, and call is reported as missing
+		calls = []*gorequests.RPCCall{{Method: "null"}, {Method: "add", Params: []int{5, 6}, Result: &a}}
+		as.Nil(client.Batch(ctx, calls))
+		as.NotNil(calls[0].Error)
+		as.Contains(calls[0].Error.Error(), "is missing")
+		as.Nil(calls[1].Error)
+		as.Equal(11, a)
+	})
+}
@@ -0,0 +1,319 @@
+// Package mock provide a programmable http.RoundTripper for test.
+//
+// inject it to gorequests.Factory, then every request created by the factory hit the mock:
+//
+//	mt := mock.New()
+//	mt.On(http.MethodGet, "https://api.example.com/users/*").WithQuery("page", "1").Reply(200, map[string]string{"name": "chyroc"})
+//	fac := gorequests.NewFactory(gorequests.WithTransport(mt))
+//	// ...
+//	mt.AssertExpectations(t)
+package mock
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TestingT is the subset of testing.TB used by Transport
+type TestingT interface {
+	Errorf(format string, args ...interface{})
+}
+
+// Transport is a http.RoundTripper response with registered expectations
+type Transport struct {
+	lock         sync.Mutex
+	expectations []*Expectation
+	unexpected   []string
+}
+
+// New create Transport
+func New() *Transport {
+	return &Transport{}
+}
+
+// On register expectation of method and url, url is matched without query, and * in url match any characters
+func (r *Transport) On(method, url string) *Expectation {
+	parts := strings.Split(url, "*")
+	for i, v := range parts {
+		parts[i] = regexp.QuoteMeta(v)
+	}
+	return r.OnRegexp(method, regexp.MustCompile("^"+strings.Join(parts, ".*")+"$"))
+}
+
+// OnRegexp register expectation of method and url regexp, url is matched without query
+func (r *Transport) OnRegexp(method string, url *regexp.Regexp) *Expectation {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	e := &Expectation{
+		method:     method,
+		url:        url,
+		query:      map[string][]string{},
+		header:     map[string][]string{},
+		status:     http.StatusOK,
+		respHeader: map[string][]string{},
+	}
+	r.expectations = append(r.expectations, e)
+	return e
+}
+
+// Reset remove all expectations and calls
+func (r *Transport) Reset() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.expectations = nil
+	r.unexpected = nil
+}
+
+// AssertExpectations assert all expectations are called as expected, and there is no unexpected request
+func (r *Transport) AssertExpectations(t TestingT) bool {
+	if h, ok := t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	ok := true
+	for _, e := range r.expectations {
+		if e.times > 0 && e.calls != e.times {
+			t.Errorf("mock: %s expect to be called %d times, but called %d times", e, e.times, e.calls)
+			ok = false
+		} else if e.times <= 0 && e.calls == 0 {
+			t.Errorf("mock: %s expect to be called, but not called", e)
+			ok = false
+		}
+	}
+	for _, v := range r.unexpected {
+		t.Errorf("mock: unexpected request %s", v)
+		ok = false
+	}
+	return ok
+}
+
+// RoundTrip implement http.RoundTripper
+func (r *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		bs, err := ioutil.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		body = bs
+	}
+
+	e := r.match(req, body)
+	if e == nil {
+		return nil, fmt.Errorf("mock: unexpected request %s %s", req.Method, req.URL.String())
+	}
+
+	if e.delay > 0 {
+		timer := time.NewTimer(e.delay)
+		defer timer.Stop()
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+
+	if e.err != nil {
+		return nil, e.err
+	}
+
+	header := e.respHeader.Clone()
+	header.Set("Content-Length", strconv.Itoa(len(e.body)))
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.status, http.StatusText(e.status)),
+		StatusCode:    e.status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(e.body)),
+		ContentLength: int64(len(e.body)),
+		Request:       req,
+	}, nil
+}
+
+func (r *Transport) match(req *http.Request, body []byte) *Expectation {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for _, e := range r.expectations {
+		if e.times > 0 && e.calls >= e.times {
+			continue
+		}
+		if e.isMatch(req, body) {
+			e.calls++
+			return e
+		}
+	}
+	r.unexpected = append(r.unexpected, req.Method+" "+req.URL.String())
+	return nil
+}
+
+// Expectation is one registered expectation, and the response of it
+type Expectation struct {
+	// match
+	method       string
+	url          *regexp.Regexp
+	query        map[string][]string
+	header       map[string][]string
+	bodyMatchers []func(body []byte) bool
+
+	// response
+	status     int
+	respHeader http.Header
+	body       []byte
+	err        error
+	delay      time.Duration
+
+	// calls
+	times int
+	calls int
+}
+
+// WithQuery expect request has query k-v
+func (r *Expectation) WithQuery(k, v string) *Expectation {
+	r.query[k] = append(r.query[k], v)
+	return r
+}
+
+// WithHeader expect request has header k-v
+func (r *Expectation) WithHeader(k, v string) *Expectation {
+	k = http.CanonicalHeaderKey(k)
+	r.header[k] = append(r.header[k], v)
+	return r
+}
+
+// WithJSONBody expect request body is json, and equal to v after both unmarshal
+func (r *Expectation) WithJSONBody(v interface{}) *Expectation {
+	var expect interface{}
+	bs, err := json.Marshal(v)
+	if err == nil {
+		err = json.Unmarshal(bs, &expect)
+	}
+	return r.WithBodyMatcher(func(body []byte) bool {
+		var actual interface{}
+		if err != nil || json.Unmarshal(body, &actual) != nil {
+			return false
+		}
+		return reflect.DeepEqual(expect, actual)
+	})
+}
+
+// WithBodyMatcher expect request body match f
+func (r *Expectation) WithBodyMatcher(f func(body []byte) bool) *Expectation {
+	r.bodyMatchers = append(r.bodyMatchers, f)
+	return r
+}
+
+// Reply set response status and body, body support: []byte, string, interface{}(as json format)
+func (r *Expectation) Reply(status int, body interface{}) *Expectation {
+	r.status = status
+	switch v := body.(type) {
+	case nil:
+		r.body = nil
+	case []byte:
+		r.body = v
+	case string:
+		r.body = []byte(v)
+	default:
+		bs, err := json.Marshal(v)
+		if err != nil {
+			r.err = err
+			return r
+		}
+		r.body = bs
+		r.respHeader.Set("Content-Type", "application/json")
+	}
+	return r
+}
+
+// ReplyHeader set response header k-v
+func (r *Expectation) ReplyHeader(k, v string) *Expectation {
+	r.respHeader.Add(k, v)
+	return r
+}
+
+// ReplyError make request failed with err
+func (r *Expectation) ReplyError(err error) *Expectation {
+	r.err = err
+	return r
+}
+
+// Delay response after d, or request context is done
+func (r *Expectation) Delay(d time.Duration) *Expectation {
+	r.delay = d
+	return r
+}
+
+// Times expect to be called n times, default is at least once
+func (r *Expectation) Times(n int) *Expectation {
+	r.times = n
+	return r
+}
+
+// Once same as Times(1)
+func (r *Expectation) Once() *Expectation {
+	return r.Times(1)
+}
+
+func (r *Expectation) String() string {
+	return r.method + " " + r.url.String()
+}
+
+func (r *Expectation) isMatch(req *http.Request, body []byte) bool {
+	if r.method != "" && r.method != req.Method {
+		return false
+	}
+
+	u := *req.URL
+	u.RawQuery, u.Fragment = "", ""
+	if !r.url.MatchString(u.String()) {
+		return false
+	}
+
+	query := req.URL.Query()
+	for k, vs := range r.query {
+		if !containsAll(query[k], vs) {
+			return false
+		}
+	}
+	for k, vs := range r.header {
+		if !containsAll(req.Header.Values(k), vs) {
+			return false
+		}
+	}
+	for _, f := range r.bodyMatchers {
+		if !f(body) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsAll(actual, expect []string) bool {
+	m := map[string]bool{}
+	for _, v := range actual {
+		m[v] = true
+	}
+	for _, v := range expect {
+		if !m[v] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,83 @@
+package mock_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/chyroc/gorequests"
+	"github.com/chyroc/gorequests/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordT struct {
+	errs []string
+}
+
+func (r *recordT) Errorf(format string, args ...interface{}) {
+	r.errs = append(r.errs, fmt.Sprintf(format, args...))
+}
+
+func Test_Mock(t *testing.T) {
+	as := assert.New(t)
+
+	t.Run("reply", func(t *testing.T) {
+		mt := mock.New()
+		mt.On(http.MethodGet, "https://api.example.com/users/*").WithQuery("page", "1").WithHeader("x-token", "t").
+			Reply(http.StatusOK, map[string]string{"name": "chyroc"}).Once()
+		mt.On(http.MethodPost, "https://api.example.com/users").WithJSONBody(map[string]interface{}{"name": "chyroc", "age": 18}).
+			Reply(http.StatusCreated, "created").ReplyHeader("x-id", "1")
+
+		fac := gorequests.NewFactory(gorequests.WithLogger(gorequests.NewDiscardLogger()), gorequests.WithTransport(mt))
+
+		resp := map[string]string{}
+		as.Nil(fac.New(http.MethodGet, "https://api.example.com/users/1").WithQuery("page", "1").WithHeader("x-token", "t").Unmarshal(&resp))
+		as.Equal("chyroc", resp["name"])
+
+		req := fac.New(http.MethodPost, "https://api.example.com/users").WithJSON(map[string]interface{}{"age": 18, "name": "chyroc"})
+		as.Equal("created", req.MustText())
+		as.Equal(http.StatusCreated, req.MustResponseStatus())
+		as.Equal("1", req.MustResponseHeaderByKey("x-id"))
+
+		as.True(mt.AssertExpectations(t))
+	})
+
+	t.Run("error and delay", func(t *testing.T) {
+		mt := mock.New()
+		mt.On(http.MethodGet, "https://api.example.com/error").ReplyError(fmt.Errorf("boom"))
+		mt.On(http.MethodGet, "https://api.example.com/delay").Delay(time.Second)
+
+		fac := gorequests.NewFactory(gorequests.WithLogger(gorequests.NewDiscardLogger()), gorequests.WithTransport(mt))
+
+		_, err := fac.New(http.MethodGet, "https://api.example.com/error").Text()
+		as.NotNil(err)
+		as.Contains(err.Error(), "boom")
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+		defer cancel()
+		_, err = fac.New(http.MethodGet, "https://api.example.com/delay").WithContext(ctx).Text()
+		as.NotNil(err)
+		as.Contains(err.Error(), "context deadline exceeded")
+	})
+
+	t.Run("assert", func(t *testing.T) {
+		mt := mock.New()
+		mt.On(http.MethodGet, "https://api.example.com/a").Times(2)
+		mt.On(http.MethodGet, "https://api.example.com/b")
+
+		fac := gorequests.NewFactory(gorequests.WithLogger(gorequests.NewDiscardLogger()), gorequests.WithTransport(mt))
+		fac.New(http.MethodGet, "https://api.example.com/a").MustText()
+		_, err := fac.New(http.MethodGet, "https://api.example.com/c").Text()
+		as.NotNil(err)
+
+		rt := &recordT{}
+		as.False(mt.AssertExpectations(rt))
+		as.Equal([]string{
+			"mock: GET ^https://api\\.example\\.com/a$ expect to be called 2 times, but called 1 times",
+			"mock: GET ^https://api\\.example\\.com/b$ expect to be called, but not called",
+			"mock: unexpected request GET https://api.example.com/c",
+		}, rt.errs)
+	})
+}
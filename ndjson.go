@@ -0,0 +1,74 @@
+package gorequests
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// EachJSONLine read newline-delimited json response by stream, and call f with every line
+//
+// blank line is skipped, line length is not limited. raw is only valid in f, copy it if need to keep.
+func (r *Request) EachJSONLine(f func(raw json.RawMessage) error) error {
+	return r.doStream(func(body io.Reader) error {
+		br := bufio.NewReader(body)
+		for lineNum := 1; ; lineNum++ {
+			line, err := readLine(br)
+			if len(line) > 0 {
+				if !json.Valid(line) {
+					return fmt.Errorf("[gorequest] %s %s invalid json at line %d: %s", r.method, r.cachedurl, lineNum, line)
+				}
+				if ferr := f(line); ferr != nil {
+					return ferr
+				}
+			}
+			if err == io.EOF {
+				return nil
+			} else if err != nil {
+				return fmt.Errorf("[gorequest] %s %s read response failed: %w", r.method, r.cachedurl, err)
+			}
+		}
+	})
+}
+
+// DecodeEach decode every line of newline-delimited json response to v, then call f
+//
+// v is reset to zero value before decoding every line, and decoding options like WithJSONUseNumber are used.
+//
+//	var item Item
+//	err := req.DecodeEach(&item, func() error {
+//		fmt.Println(item)
+//		return nil
+//	})
+func (r *Request) DecodeEach(v interface{}, f func() error) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("[gorequest] %s %s decode each need non-nil pointer, but got %T", r.method, r.cachedurl, v)
+	}
+	rv = rv.Elem()
+
+	return r.EachJSONLine(func(raw json.RawMessage) error {
+		rv.Set(reflect.Zero(rv.Type()))
+		if err := r.decodeJSON(bytes.NewReader(raw), v); err != nil {
+			return fmt.Errorf("[gorequest] %s %s unmarshal %s to %T failed: %w", r.method, r.cachedurl, raw, v, err)
+		}
+		return f()
+	})
+}
+
+// readLine read one line without trailing "\r\n" or "\n", without size limit like bufio.Scanner
+func readLine(br *bufio.Reader) ([]byte, error) {
+	line, err := br.ReadSlice('\n')
+	if err == bufio.ErrBufferFull {
+		buf := append([]byte(nil), line...)
+		for err == bufio.ErrBufferFull {
+			line, err = br.ReadSlice('\n')
+			buf = append(buf, line...)
+		}
+		line = buf
+	}
+	return bytes.TrimSpace(line), err
+}
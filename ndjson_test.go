@@ -0,0 +1,77 @@
+package gorequests_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/chyroc/gorequests"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NDJSON(t *testing.T) {
+	as := assert.New(t)
+
+	longName := strings.Repeat("x", 200*1024) // longer than bufio.Scanner default limit
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		switch r.URL.Path {
+		case "/invalid":
+			_, _ = fmt.Fprint(w, "{\"id\":1}\n{\"id\":\n")
+		default:
+			_, _ = fmt.Fprintf(w, "{\"id\":1,\"tags\":[\"a\"]}\r\n\n{\"id\":2,\"name\":%q}\n{\"id\":3}", longName)
+		}
+	}))
+	defer server.Close()
+
+	t.Run("each line", func(t *testing.T) {
+		var lines []string
+		as.Nil(gorequests.New(http.MethodGet, server.URL).EachJSONLine(func(raw json.RawMessage) error {
+			lines = append(lines, string(raw[:8]))
+			return nil
+		}))
+		as.Equal([]string{`{"id":1,`, `{"id":2,`, `{"id":3}`}, lines)
+	})
+
+	t.Run("decode each", func(t *testing.T) {
+		type item struct {
+			ID   int      `json:"id"`
+			Name string   `json:"name"`
+			Tags []string `json:"tags"`
+		}
+		var items []item
+		var v item
+		as.Nil(gorequests.New(http.MethodGet, server.URL).DecodeEach(&v, func() error {
+			items = append(items, v)
+			return nil
+		}))
+		as.Len(items, 3)
+		as.Equal(item{ID: 1, Tags: []string{"a"}}, items[0])
+		as.Equal(longName, items[1].Name)
+		as.Equal(item{ID: 3}, items[2])
+	})
+
+	t.Run("stop", func(t *testing.T) {
+		stop := errors.New("stop")
+		count := 0
+		err := gorequests.New(http.MethodGet, server.URL).EachJSONLine(func(raw json.RawMessage) error {
+			count++
+			return stop
+		})
+		as.Equal(stop, err)
+		as.Equal(1, count)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		err := gorequests.New(http.MethodGet, server.URL+"/invalid").EachJSONLine(func(raw json.RawMessage) error { return nil })
+		as.NotNil(err)
+		as.Contains(err.Error(), "line 2")
+
+		err = gorequests.New(http.MethodGet, server.URL).DecodeEach(struct{}{}, func() error { return nil })
+		as.NotNil(err)
+	})
+}
@@ -1,6 +1,7 @@
 package gorequests
 
 import (
+	"net/http"
 	"time"
 )
 
@@ -33,3 +34,122 @@ func WithQuery(key, val string) RequestOption {
 		return nil
 	}
 }
+
+func WithBaseURL(baseURL string) RequestOption {
+	return func(req *Request) error {
+		req.WithBaseURL(baseURL)
+		return nil
+	}
+}
+
+func WithDefaultQuery(key, val string) RequestOption {
+	return func(req *Request) error {
+		req.WithDefaultQuery(key, val)
+		return nil
+	}
+}
+
+func WithPathParam(key, val string) RequestOption {
+	return func(req *Request) error {
+		req.WithPathParam(key, val)
+		return nil
+	}
+}
+
+func WithOrderedQuery(b bool) RequestOption {
+	return func(req *Request) error {
+		req.WithOrderedQuery(b)
+		return nil
+	}
+}
+
+func WithQueryEscaper(escaper func(s string) string) RequestOption {
+	return func(req *Request) error {
+		req.WithQueryEscaper(escaper)
+		return nil
+	}
+}
+
+func WithTransport(rt http.RoundTripper) RequestOption {
+	return func(req *Request) error {
+		req.WithTransport(rt)
+		return nil
+	}
+}
+
+func WithWrapTransport(f func(rt http.RoundTripper) http.RoundTripper) RequestOption {
+	return func(req *Request) error {
+		req.WithWrapTransport(f)
+		return nil
+	}
+}
+
+func WithHARRecorder(recorder *HARRecorder) RequestOption {
+	return func(req *Request) error {
+		req.WithHARRecorder(recorder)
+		return nil
+	}
+}
+
+func WithCassette(cassette *Cassette) RequestOption {
+	return func(req *Request) error {
+		req.WithCassette(cassette)
+		return nil
+	}
+}
+
+func WithFaultInjector(injector *FaultInjector) RequestOption {
+	return func(req *Request) error {
+		req.WithFaultInjector(injector)
+		return nil
+	}
+}
+
+func WithCache(cache *Cache) RequestOption {
+	return func(req *Request) error {
+		req.WithCache(cache)
+		return nil
+	}
+}
+
+func WithDecompress(b bool) RequestOption {
+	return func(req *Request) error {
+		req.WithDecompress(b)
+		return nil
+	}
+}
+
+func WithResponseCharset(name string) RequestOption {
+	return func(req *Request) error {
+		req.WithResponseCharset(name)
+		return nil
+	}
+}
+
+func WithJSONUseNumber(b bool) RequestOption {
+	return func(req *Request) error {
+		req.WithJSONUseNumber(b)
+		return nil
+	}
+}
+
+func WithJSONDisallowUnknownFields(b bool) RequestOption {
+	return func(req *Request) error {
+		req.WithJSONDisallowUnknownFields(b)
+		return nil
+	}
+}
+
+func WithStreamUnmarshal(b bool) RequestOption {
+	return func(req *Request) error {
+		req.WithStreamUnmarshal(b)
+		return nil
+	}
+}
+
+func WithSSEMaxRetries(n int) RequestOption {
+	return func(req *Request) error {
+		req.WithSSEMaxRetries(n)
+		return nil
+	}
+}
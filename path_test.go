@@ -0,0 +1,64 @@
+package gorequests_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/chyroc/gorequests"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_PathParam(t *testing.T) {
+	as := assert.New(t)
+
+	t.Run("escape", func(t *testing.T) {
+		req := gorequests.New(http.MethodGet, "https://example.com/users/{id}/repos/{repo}?q={raw}").
+			WithPathParam("id", "42").
+			WithPathParams(map[string]string{"repo": "../a b/c?d"})
+		as.Equal("https://example.com/users/42/repos/..%2Fa%20b%2Fc%3Fd?q=%7Braw%7D", req.RequestFullURL())
+	})
+
+	t.Run("struct", func(t *testing.T) {
+		type params struct {
+			ID    int      `path:"id"`
+			Tags  []string `path:"tags"`
+			Other string
+		}
+		req := gorequests.New(http.MethodGet, "https://example.com/users/{id}/{tags}").WithPathStruct(params{ID: 1, Tags: []string{"a", "b"}})
+		as.Equal("https://example.com/users/1/a%2Cb", req.RequestFullURL())
+
+		_, err := gorequests.New(http.MethodGet, "https://example.com/{id}").WithPathStruct(1).Text()
+		as.NotNil(err)
+	})
+
+	t.Run("unfilled", func(t *testing.T) {
+		_, err := gorequests.New(http.MethodGet, joinHttpBinURL("/anything/{id}/{name}")).WithPathParam("id", "1").Text()
+		as.NotNil(err)
+		as.Contains(err.Error(), "path param name is not set")
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, val := range []string{"..", ".", ""} {
+			_, err := gorequests.New(http.MethodGet, "https://example.com/users/{id}/repos").WithPathParam("id", val).Text()
+			as.NotNil(err, val)
+			as.Contains(err.Error(), "path param id=")
+			as.Contains(err.Error(), "is invalid")
+		}
+
+		fac := gorequests.NewFactory(gorequests.WithBaseURL("https://api.example.com/v2/"))
+		_, err := fac.New(http.MethodGet, "users/{id}").WithPathParam("id", "..").Text()
+		as.NotNil(err)
+		as.Contains(err.Error(), `path param id=".." is invalid`)
+
+		// value with ':' is not parsed as scheme of url
+		as.Equal("https://api.example.com/v2/evil%3Ax/repos", fac.New(http.MethodGet, "{id}/repos").WithPathParam("id", "evil:x").RequestFullURL())
+		as.Equal("https://example.com/users/12%3A30", gorequests.New(http.MethodGet, "https://example.com/users/{id}").WithPathParam("id", "12:30").RequestFullURL())
+	})
+
+	t.Run("factory", func(t *testing.T) {
+		fac := gorequests.NewFactory(gorequests.WithPathParam("version", "v1"), gorequests.WithLogger(gorequests.NewDiscardLogger()))
+		val, err := fac.New(http.MethodGet, joinHttpBinURL("/anything/{version}/users/{id}")).WithPathParam("id", "a/b").Get("url")
+		as.Nil(err)
+		as.Contains(val.String(), "/anything/v1/users/a%2Fb")
+	})
+}
@@ -0,0 +1,60 @@
+package gorequests
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// ProtobufJSONCodec encode and decode proto.Message with protobuf json mapping, it's not registered to any media type
+var ProtobufJSONCodec Codec = protobufJSONCodec{}
+
+// WithProtobuf set body as binary protobuf, and set Content-Type to application/x-protobuf
+func (r *Request) WithProtobuf(msg proto.Message) *Request {
+	return r.WithBodyAs(ProtobufCodec, msg)
+}
+
+// WithProtobufJSON set body as protobuf json mapping, and set Content-Type to application/json
+func (r *Request) WithProtobufJSON(msg proto.Message) *Request {
+	return r.WithBodyAs(ProtobufJSONCodec, msg)
+}
+
+// UnmarshalProtobuf decode response as binary protobuf, ignore Content-Type
+func (r *Request) UnmarshalProtobuf(msg proto.Message) error {
+	return r.UnmarshalAs(ProtobufCodec, msg)
+}
+
+func (r *Request) MustUnmarshalProtobuf(msg proto.Message) {
+	_ = r.UnmarshalProtobuf(msg)
+}
+
+// UnmarshalProtobufJSON decode response as protobuf json mapping, unknown fields are ignored
+func (r *Request) UnmarshalProtobufJSON(msg proto.Message) error {
+	return r.UnmarshalAs(ProtobufJSONCodec, msg)
+}
+
+func (r *Request) MustUnmarshalProtobufJSON(msg proto.Message) {
+	_ = r.UnmarshalProtobufJSON(msg)
+}
+
+// protobuf json, value must be proto.Message
+type protobufJSONCodec struct{}
+
+func (protobufJSONCodec) ContentType() string { return "application/json" }
+
+func (protobufJSONCodec) Marshal(v interface{}) ([]byte, error) {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("need proto.Message, but got %T", v)
+	}
+	return protojson.Marshal(msg)
+}
+
+func (protobufJSONCodec) Unmarshal(data []byte, v interface{}) error {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("need proto.Message, but got %T", v)
+	}
+	return protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(data, msg)
+}
@@ -0,0 +1,43 @@
+package gorequests_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chyroc/gorequests"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func Test_Protobuf(t *testing.T) {
+	as := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bs, _ := ioutil.ReadAll(r.Body)
+		w.Header().Set("Content-Type", r.Header.Get("Content-Type"))
+		_, _ = w.Write(bs)
+	}))
+	defer server.Close()
+
+	fac := gorequests.NewFactory(gorequests.WithLogger(gorequests.NewDiscardLogger()))
+	msg, err := structpb.NewStruct(map[string]interface{}{"name": "chyroc", "age": 18})
+	as.Nil(err)
+
+	t.Run("binary", func(t *testing.T) {
+		req := fac.New(http.MethodPost, server.URL).WithProtobuf(msg)
+		resp := &structpb.Struct{}
+		as.Nil(req.UnmarshalProtobuf(resp))
+		as.Equal("application/x-protobuf", req.MustResponseHeaderByKey("Content-Type"))
+		as.Equal(msg.AsMap(), resp.AsMap())
+	})
+
+	t.Run("json", func(t *testing.T) {
+		req := fac.New(http.MethodPost, server.URL).WithProtobufJSON(msg)
+		resp := &structpb.Struct{}
+		as.Nil(req.UnmarshalProtobufJSON(resp))
+		as.Equal("application/json", req.MustResponseHeaderByKey("Content-Type"))
+		as.Equal(msg.AsMap(), resp.AsMap())
+	})
+}
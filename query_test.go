@@ -0,0 +1,162 @@
+package gorequests_test
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/chyroc/gorequests"
+	"github.com/stretchr/testify/assert"
+)
+
+type queryPage struct {
+	Page int `query:"page,omitempty"`
+	Size int `query:"size"`
+}
+
+type queryLevel int
+
+func (r queryLevel) String() string { return [...]string{"low", "high"}[r] }
+
+func Test_QueryStruct(t *testing.T) {
+	as := assert.New(t)
+
+	parse := func(req *gorequests.Request) url.Values {
+		u, err := url.Parse(req.RequestFullURL())
+		as.Nil(err)
+		return u.Query()
+	}
+
+	t.Run("basic", func(t *testing.T) {
+		type query struct {
+			Name   string `query:"name"`
+			Age    int    `query:"age"`
+			OK     bool   `query:"ok"`
+			IDs    []uint `query:"ids"`
+			Ignore string `query:"-"`
+			NoTag  string
+		}
+		q := parse(gorequests.New(http.MethodGet, "https://example.com").WithQueryStruct(query{Name: "a", Age: 1, OK: true, IDs: []uint{1, 2}, Ignore: "x", NoTag: "y"}))
+		as.Equal(url.Values{"name": {"a"}, "age": {"1"}, "ok": {"true"}, "ids": {"1", "2"}}, q)
+	})
+
+	t.Run("options", func(t *testing.T) {
+		type query struct {
+			queryPage
+			*Extra
+			Score   float64                `query:"score"`
+			Ratio   float32                `query:"ratio,omitempty"`
+			Created time.Time              `query:"created"`
+			Start   time.Time              `query:"start,unix"`
+			End     *time.Time             `query:"end,unixmilli"`
+			Skip    *int                   `query:"skip"`
+			Empty   string                 `query:"empty,omitempty"`
+			Zero    string                 `query:"zero"`
+			Tags    []string               `query:"tags,comma"`
+			Items   []int                  `query:"items,brackets"`
+			NoItems []int                  `query:"no_items,brackets,omitempty"`
+			Level   queryLevel             `query:"level"`
+			IP      net.IP                 `query:"ip"`
+			Filter  filter                 `query:"filter"`
+			Meta    map[string]interface{} `query:"meta"`
+		}
+		tm := time.Date(2021, 1, 2, 3, 4, 5, 6000000, time.UTC)
+		q := parse(gorequests.New(http.MethodGet, "https://example.com").WithQueryStruct(&query{
+			queryPage: queryPage{Size: 10},
+			Score:     1.5,
+			Created:   tm,
+			Start:     tm,
+			End:       &tm,
+			Tags:      []string{"a", "b"},
+			Items:     []int{1, 2},
+			Level:     1,
+			IP:        net.ParseIP("127.0.0.1"),
+			Filter:    filter{Status: "open", Range: &queryPage{Page: 2}},
+			Meta:      map[string]interface{}{"b": 1, "a": "x"},
+		}))
+		as.Equal(url.Values{
+			"size":                {"10"},
+			"score":               {"1.5"},
+			"created":             {"2021-01-02T03:04:05Z"},
+			"start":               {"1609556645"},
+			"end":                 {"1609556645006"},
+			"zero":                {""},
+			"tags":                {"a,b"},
+			"items[]":             {"1", "2"},
+			"level":               {"high"},
+			"ip":                  {"127.0.0.1"},
+			"filter[status]":      {"open"},
+			"filter[range][page]": {"2"},
+			"filter[range][size]": {"0"},
+			"meta[a]":             {"x"},
+			"meta[b]":             {"1"},
+		}, q)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		type query struct {
+			C chan int `query:"c"`
+		}
+		_, err := gorequests.New(http.MethodGet, joinHttpBinURL("/get")).WithQueryStruct(query{C: make(chan int)}).Text()
+		as.NotNil(err)
+
+		_, err = gorequests.New(http.MethodGet, joinHttpBinURL("/get")).WithQueryStruct("a").Text()
+		as.NotNil(err)
+	})
+}
+
+// Extra is embedded pointer, skipped when nil
+type Extra struct {
+	Extra string `query:"extra"`
+}
+
+type filter struct {
+	Status string     `query:"status"`
+	Range  *queryPage `query:"range"`
+	Other  *queryPage `query:"other"`
+}
+
+func Test_OrderedQuery(t *testing.T) {
+	as := assert.New(t)
+
+	t.Run("default sorted", func(t *testing.T) {
+		req := gorequests.New(http.MethodGet, "https://example.com/a?z=1&b=a%20b").WithQuery("c", "x y").WithQuery("a", "1")
+		as.Equal("https://example.com/a?a=1&b=a+b&c=x+y&z=1", req.RequestFullURL())
+
+		req = gorequests.New(http.MethodGet, "https://example.com/a?z=1").WithQuery("c", "x y*").WithQueryEscaper(gorequests.QueryEscapeRFC3986)
+		as.Equal("https://example.com/a?c=x%20y%2A&z=1", req.RequestFullURL())
+	})
+
+	t.Run("ordered", func(t *testing.T) {
+		type query struct {
+			Y string `query:"y"`
+			X string `query:"x"`
+		}
+		req := gorequests.New(http.MethodGet, "https://example.com/a?z=1&b=a%20b&s=a,b").
+			WithOrderedQuery(true).
+			WithDefaultQuery("d", "default").
+			WithDefaultQuery("z", "ignored").
+			WithQuery("c", "x y").
+			WithQueryStruct(query{Y: "2", X: "1"}).
+			WithQuerys(map[string]string{"n": "2", "m": "1"}).
+			WithQuery("c", "again")
+		as.Equal("https://example.com/a?z=1&b=a%20b&s=a,b&c=x+y&y=2&x=1&m=1&n=2&c=again&d=default", req.RequestFullURL())
+
+		req = gorequests.New(http.MethodGet, "https://example.com/a").
+			WithOrderedQuery(true).
+			WithQueryEscaper(gorequests.QueryEscapeRFC3986).
+			WithQuery("k~", "a b+c/é")
+		as.Equal("https://example.com/a?k~=a%20b%2Bc%2F%C3%A9", req.RequestFullURL())
+
+		as.Equal("https://example.com/a", gorequests.New(http.MethodGet, "https://example.com/a").WithOrderedQuery(true).RequestFullURL())
+	})
+
+	t.Run("send", func(t *testing.T) {
+		fac := gorequests.NewFactory(gorequests.WithOrderedQuery(true), gorequests.WithQueryEscaper(gorequests.QueryEscapeRFC3986))
+		val, err := fac.New(http.MethodGet, joinHttpBinURL("/get?b=1")).WithQuery("a", "x y").Get("url")
+		as.Nil(err)
+		as.Equal(joinHttpBinURL("/get?b=1&a=x%20y"), val.String())
+	})
+}
@@ -5,7 +5,6 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"mime/multipart"
 	"net/http"
 	"net/url"
 	"strings"
@@ -37,7 +36,8 @@ func (r *Request) RequestFullURL() string {
 	r.lock.RLock()
 	defer r.lock.RUnlock()
 
-	return r.parseRequestURL()
+	u, _ := r.parseRequestURL()
+	return u
 }
 
 // Method request method
@@ -52,7 +52,7 @@ func (r *Request) RequestHeader() http.Header {
 
 // ----- set params
 
-// WithContext setup request context.Context
+// WithContext setup request context.Context, it is used to send request, so cancel or deadline of ctx abort the request
 func (r *Request) WithContext(ctx context.Context) *Request {
 	return r.configParamFactor(func(r *Request) {
 		r.context = ctx
@@ -67,6 +67,9 @@ func (r *Request) WithTimeout(timeout time.Duration) *Request {
 }
 
 // WithIgnoreSSL ignore ssl verify
+//
+// if transport is set by WithTransport, it must be *http.Transport, which is cloned with InsecureSkipVerify,
+// other transport make request failed.
 func (r *Request) WithIgnoreSSL(ignore bool) *Request {
 	return r.configParamFactor(func(r *Request) {
 		r.isIgnoreSSL = ignore
@@ -80,6 +83,42 @@ func (r *Request) WithWrapRoundTripperResponse(f func(resp *http.Response) (*htt
 	})
 }
 
+// WithTransport set the base http.RoundTripper used to send request
+//
+// WithIgnoreSSL only support *http.Transport, request with other transport and WithIgnoreSSL(true) is failed.
+func (r *Request) WithTransport(rt http.RoundTripper) *Request {
+	return r.configParamFactor(func(r *Request) {
+		r.transport = rt
+	})
+}
+
+// WithWrapTransport wrap the http.RoundTripper used to send request, wrapper added later is outermost
+func (r *Request) WithWrapTransport(f func(rt http.RoundTripper) http.RoundTripper) *Request {
+	return r.configParamFactor(func(r *Request) {
+		r.wrapTransports = append(r.wrapTransports, f)
+	})
+}
+
+// WithHARRecorder record request and response to HARRecorder
+func (r *Request) WithHARRecorder(recorder *HARRecorder) *Request {
+	return r.WithWrapTransport(recorder.RoundTripper)
+}
+
+// WithCassette record or replay request with Cassette
+func (r *Request) WithCassette(cassette *Cassette) *Request {
+	return r.WithWrapTransport(cassette.RoundTripper)
+}
+
+// WithFaultInjector inject fault to request with FaultInjector
+func (r *Request) WithFaultInjector(injector *FaultInjector) *Request {
+	return r.WithWrapTransport(injector.RoundTripper)
+}
+
+// WithCache serve response from Cache when possible
+func (r *Request) WithCache(cache *Cache) *Request {
+	return r.WithWrapTransport(cache.RoundTripper)
+}
+
 // WithHeader set one header k-v map
 func (r *Request) WithHeader(k, v string) *Request {
 	return r.configParamFactor(func(r *Request) {
@@ -112,18 +151,124 @@ func (r *Request) WithRedirect(b bool) *Request {
 	})
 }
 
+// WithDecompress set decode or not decode response body with Content-Encoding header, default is decode
+//
+// when body is decoded, Content-Encoding and Content-Length are removed from response headers.
+func (r *Request) WithDecompress(b bool) *Request {
+	return r.configParamFactor(func(r *Request) {
+		r.isNoDecompress = !b
+	})
+}
+
+// WithResponseCharset force charset used by Text to decode response, instead of detect it
+func (r *Request) WithResponseCharset(name string) *Request {
+	return r.configParamFactor(func(r *Request) {
+		if _, err := lookupCharset(name); err != nil {
+			r.err = err
+			return
+		}
+		r.responseCharset = name
+	})
+}
+
+// WithJSONUseNumber set decode json number as json.Number instead of float64, for Unmarshal and Map
+func (r *Request) WithJSONUseNumber(b bool) *Request {
+	return r.configParamFactor(func(r *Request) {
+		r.jsonUseNumber = b
+	})
+}
+
+// WithJSONDisallowUnknownFields set return error when json object has field not in struct, for Unmarshal
+func (r *Request) WithJSONDisallowUnknownFields(b bool) *Request {
+	return r.configParamFactor(func(r *Request) {
+		r.jsonDisallowUnknownFields = b
+	})
+}
+
+// WithStreamUnmarshal set Unmarshal and Map decode json from response body by stream, without buffering it by Bytes
+//
+// response body can not be read again after stream decoding.
+func (r *Request) WithStreamUnmarshal(b bool) *Request {
+	return r.configParamFactor(func(r *Request) {
+		r.isStreamUnmarshal = b
+	})
+}
+
 // WithQuery set one query k-v map
 func (r *Request) WithQuery(k, v string) *Request {
 	return r.configParamFactor(func(r *Request) {
-		r.querys[k] = append(r.querys[k], v)
+		r.addQuery(k, v)
 	})
 }
 
-// WithQuerys set multi query k-v map
+// WithQuerys set multi query k-v map, keys are added in sorted order
 func (r *Request) WithQuerys(kv map[string]string) *Request {
+	return r.configParamFactor(func(r *Request) {
+		for _, k := range sortedKeys(kv) {
+			r.addQuery(k, kv[k])
+		}
+	})
+}
+
+// WithOrderedQuery set keep query in order: raw query of url is kept as-is,
+// and query added by WithQuery, WithQuerys, WithQueryStruct is appended in insertion order
+//
+// default is false, all query are merged and sorted by key.
+func (r *Request) WithOrderedQuery(b bool) *Request {
+	return r.configParamFactor(func(r *Request) {
+		r.isOrderedQuery = b
+	})
+}
+
+// WithQueryEscaper set function to escape key and value of query, default is url.QueryEscape
+//
+// raw query of url is not escaped again in ordered mode, see WithOrderedQuery.
+func (r *Request) WithQueryEscaper(escaper func(s string) string) *Request {
+	return r.configParamFactor(func(r *Request) {
+		r.queryEscaper = escaper
+	})
+}
+
+// WithBaseURL set base url, request url is resolved against it by RFC 3986, like: `users/1` with `https://api.example.com/v2/`
+func (r *Request) WithBaseURL(baseURL string) *Request {
+	return r.configParamFactor(func(r *Request) {
+		r.baseURL = baseURL
+	})
+}
+
+// WithDefaultQuery add default query, which is used only if query key is not set by url or WithQuery
+func (r *Request) WithDefaultQuery(key, val string) *Request {
+	return r.configParamFactor(func(r *Request) {
+		r.defaultQuerys[key] = append(r.defaultQuerys[key], val)
+	})
+}
+
+// WithPathParam set path param, which replace {key} in url, value is escaped by url.PathEscape and ':' is escaped, empty, . or .. is error
+func (r *Request) WithPathParam(key, val string) *Request {
+	return r.configParamFactor(func(r *Request) {
+		r.pathParams[key] = val
+	})
+}
+
+// WithPathParams set multi path params
+func (r *Request) WithPathParams(kv map[string]string) *Request {
 	return r.configParamFactor(func(r *Request) {
 		for k, v := range kv {
-			r.querys[k] = append(r.querys[k], v)
+			r.pathParams[k] = v
+		}
+	})
+}
+
+// WithPathStruct set path params by struct field with tag `path:"key"`, slice value is joined with comma before escaping
+func (r *Request) WithPathStruct(v interface{}) *Request {
+	return r.configParamFactor(func(r *Request) {
+		kv, err := structToMap(v, "path")
+		if err != nil {
+			r.err = err
+			return
+		}
+		for k, v := range kv {
+			r.pathParams[k] = strings.Join(v, ",")
 		}
 	})
 }
@@ -131,28 +276,38 @@ func (r *Request) WithQuerys(kv map[string]string) *Request {
 // WithQueryStruct set multi query k-v map
 func (r *Request) WithQueryStruct(v interface{}) *Request {
 	return r.configParamFactor(func(r *Request) {
-		kv, err := queryToMap(v)
+		pairs, err := structToPairs(v, "query")
 		if err != nil {
 			r.err = err
 			return
 		}
-		for k, v := range kv {
-			r.querys[k] = append(r.querys[k], v...)
+		for _, p := range pairs {
+			if p.file != nil {
+				r.err = fmt.Errorf("file field %s is only supported by multipart form", p.key)
+				return
+			}
+			r.addQuery(p.key, p.val)
 		}
 	})
 }
 
-// WithBody set request body, support: io.Reader, []byte, string, interface{}(as json format)
+// WithBody set request body, support: io.Reader, []byte, string, interface{}
+//
+// interface{} is encoded by codec registered for Content-Type header already set, or as json format.
 func (r *Request) WithBody(body interface{}) *Request {
 	return r.configParamFactor(func(r *Request) {
-		r.rawBody, r.body, r.err = toBody(body)
+		codec, ok := LookupCodec(r.header.Get("Content-Type"))
+		if !ok {
+			codec = JSONCodec
+		}
+		r.rawBody, r.body, r.err = toBody(body, codec)
 	})
 }
 
 // WithJSON set body same as WithBody, and set Content-Type to application/json
 func (r *Request) WithJSON(body interface{}) *Request {
 	return r.configParamFactor(func(r *Request) {
-		r.rawBody, r.body, r.err = toBody(body)
+		r.rawBody, r.body, r.err = toBody(body, JSONCodec)
 		if r.err != nil {
 			return
 		}
@@ -160,36 +315,93 @@ func (r *Request) WithJSON(body interface{}) *Request {
 	})
 }
 
+// WithXML set body same as WithBody, but interface{} is encoded as xml, and set Content-Type to application/xml
+func (r *Request) WithXML(body interface{}) *Request {
+	return r.configParamFactor(func(r *Request) {
+		r.rawBody, r.body, r.err = toBody(body, XMLCodec)
+		if r.err != nil {
+			return
+		}
+		r.header.Set("Content-Type", "application/xml")
+	})
+}
+
+// WithBodyAs set body encoded by codec, and set Content-Type to codec.ContentType()
+func (r *Request) WithBodyAs(codec Codec, body interface{}) *Request {
+	return r.configParamFactor(func(r *Request) {
+		r.rawBody, r.body, r.err = toCodecBody(codec, body)
+		if r.err != nil {
+			return
+		}
+		r.header.Set("Content-Type", codec.ContentType())
+	})
+}
+
 // WithForm set body and set Content-Type to multiform
-func (r *Request) WithForm(body map[string]string) *Request {
+//
+// body support: map[string]string, url.Values, map[string][]string, map[string]interface{},
+// and struct with `form:"name"` tag, which can contain FormFile field.
+func (r *Request) WithForm(body interface{}) *Request {
 	return r.configParamFactor(func(r *Request) {
-		buf := bytes.Buffer{}
-		f := multipart.NewWriter(&buf)
-		for k, v := range body {
-			if err := f.WriteField(k, v); err != nil {
-				r.err = err
-				return
-			}
+		pairs, err := formToPairs(body)
+		if err != nil {
+			r.err = err
+			return
+		}
+		contentType, bs, err := newMultipartForm(pairs)
+		if err != nil {
+			r.err = err
+			return
 		}
 
-		r.rawBody, r.body = buf.Bytes(), strings.NewReader(buf.String())
-		r.header.Set("Content-Type", f.FormDataContentType())
+		r.rawBody, r.body = bs, bytes.NewReader(bs)
+		r.header.Set("Content-Type", contentType)
 	})
 }
 
 // WithFormURLEncoded set body and set Content-Type to application/x-www-form-urlencoded
-func (r *Request) WithFormURLEncoded(body map[string]string) *Request {
+//
+// body support: map[string]string, url.Values, map[string][]string, map[string]interface{}, and struct with `form:"name"` tag.
+// fields are encoded in order, and repeated key is supported.
+func (r *Request) WithFormURLEncoded(body interface{}) *Request {
 	return r.configParamFactor(func(r *Request) {
-		u := url.Values{}
-		for k, v := range body {
-			u.Add(k, v)
+		pairs, err := formToPairs(body)
+		if err != nil {
+			r.err = err
+			return
+		}
+		buf := strings.Builder{}
+		for _, p := range pairs {
+			if p.file != nil {
+				r.err = fmt.Errorf("file field %s is only supported by multipart form", p.key)
+				return
+			}
+			if buf.Len() > 0 {
+				buf.WriteByte('&')
+			}
+			buf.WriteString(url.QueryEscape(p.key))
+			buf.WriteByte('=')
+			buf.WriteString(url.QueryEscape(p.val))
 		}
 
-		r.rawBody, r.body = []byte(u.Encode()), strings.NewReader(u.Encode())
+		r.rawBody, r.body = []byte(buf.String()), strings.NewReader(buf.String())
 		r.header.Set("Content-Type", "application/x-www-form-urlencoded")
 	})
 }
 
+// WithCompressedBody compress request body with encoding when send, and set Content-Encoding, support: gzip, deflate, br, zstd
+//
+// request without body is sent as is, without Content-Encoding.
+func (r *Request) WithCompressedBody(encoding string) *Request {
+	return r.configParamFactor(func(r *Request) {
+		if err := checkContentEncoding(encoding); err != nil {
+			r.err = err
+			return
+		}
+		r.bodyEncoding = encoding
+	})
+}
+
 // WithFile set file to body and set some multi-form k-v map
 func (r *Request) WithFile(filename string, file io.Reader, fileKey string, params map[string]string) *Request {
 	return r.configParamFactor(func(r *Request) {
@@ -233,7 +445,12 @@ func (r *Request) WithLogger(logger Logger) *Request {
 	})
 }
 
-// WithHeader set one header k-v map
+// addQuery add query to map, and keep insertion order
+func (r *Request) addQuery(k, v string) {
+	r.querys[k] = append(r.querys[k], v)
+	r.queryPairs = append(r.queryPairs, queryPair{key: k, val: v})
+}
+
 func (r *Request) configParamFactor(f func(*Request)) *Request {
 	r.lock.Lock()
 	defer r.lock.Unlock()
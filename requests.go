@@ -20,33 +20,52 @@ type Request struct {
 	logger        Logger
 
 	// request
-	context      context.Context     // request context
-	isIgnoreSSL  bool                // request  ignore ssl verify
-	header       http.Header         // request header
-	querys       map[string][]string // request query
-	isNoRedirect bool                // request ignore redirect
-	timeout      time.Duration       // request timeout
-	url          string              // request url
-	method       string              // request method
-	rawBody      []byte              // []byte of body
-	body         io.Reader           // request body
+	context        context.Context     // request context
+	isIgnoreSSL    bool                // request  ignore ssl verify
+	header         http.Header         // request header
+	querys         map[string][]string // request query
+	queryPairs     []queryPair         // request query in insertion order
+	isOrderedQuery bool                // request query keep raw query and insertion order
+	queryEscaper   func(string) string // request query escaper, default is url.QueryEscape
+	pathParams     map[string]string   // request path params, replace {key} in url
+	baseURL        string              // request base url, url is resolved against it
+	defaultQuerys  map[string][]string // request default query, used if query key is not set
+	isNoRedirect   bool                // request ignore redirect
+	timeout        time.Duration       // request timeout
+	url            string              // request url
+	method         string              // request method
+	rawBody        []byte              // []byte of body
+	body           io.Reader           // request body
+	bodyEncoding   string              // request body compress encoding
 
 	// resp
-	wrapRoundTripperResponse func(resp *http.Response) (*http.Response, error) // wrap response
-	resp                     *http.Response
-	bytes                    []byte
-	isRead                   bool
-	isRequest                bool
+	wrapRoundTripperResponse  func(resp *http.Response) (*http.Response, error) // wrap response
+	transport                 http.RoundTripper                                 // base round tripper
+	wrapTransports            []func(http.RoundTripper) http.RoundTripper       // wrap round tripper, the last one is outermost
+	resp                      *http.Response
+	bytes                     []byte
+	isNoDecompress            bool   // not decode response body with Content-Encoding
+	responseCharset           string // force response charset used by Text
+	jsonUseNumber             bool   // decode json number as json.Number
+	jsonDisallowUnknownFields bool   // decode json with unknown fields as error
+	isStreamUnmarshal         bool   // decode json from response body by stream, without buffering
+	sseMaxRetries             int    // max times of consecutive failed reconnect of SSE, negative means unlimited
+	isRead                    bool
+	isStreamed                bool // response body is read by stream, and not buffered
+	isRequest                 bool
 }
 
 func New(method, url string) *Request {
 	r := &Request{
-		url:     url,
-		method:  method,
-		header:  map[string][]string{},
-		querys:  make(map[string][]string),
-		context: context.TODO(),
-		logger:  NewStdoutLogger(),
+		url:           url,
+		method:        method,
+		header:        map[string][]string{},
+		querys:        make(map[string][]string),
+		pathParams:    map[string]string{},
+		defaultQuerys: map[string][]string{},
+		context:       context.TODO(),
+		logger:        NewStdoutLogger(),
+		sseMaxRetries: defaultSSEMaxRetries,
 	}
 	r.header.Set("user-agent", fmt.Sprintf("gorequests/%s (https://github.com/chyroc/gorequests)", version))
 	return r
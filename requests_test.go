@@ -1,10 +1,13 @@
 package gorequests_test
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"regexp"
 	"strings"
@@ -12,14 +15,17 @@ import (
 	"time"
 
 	"github.com/chyroc/gorequests"
+	"github.com/chyroc/gorequests/httpbintest"
 	"github.com/stretchr/testify/assert"
 )
 
+var httpbinServer = httpbintest.NewServer()
+
 func joinHttpBinURL(path string) string {
 	if !strings.HasPrefix(path, "/") {
 		path = "/" + path
 	}
-	return "https://httpbin.org" + path
+	return httpbinServer.URL + path
 }
 
 func Test_Real(t *testing.T) {
@@ -209,3 +215,49 @@ func newTestHttpServer() {
 		panic(err)
 	}
 }
+
+func Test_Context(t *testing.T) {
+	as := assert.New(t)
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := gorequests.New(http.MethodGet, joinHttpBinURL("/get")).WithContext(ctx).Text()
+		as.True(errors.Is(err, context.Canceled), err)
+	})
+
+	t.Run("deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+		defer cancel()
+		_, err := gorequests.New(http.MethodGet, joinHttpBinURL("/delay/2")).WithContext(ctx).Text()
+		as.True(errors.Is(err, context.DeadlineExceeded), err)
+	})
+}
+
+func Test_IgnoreSSL(t *testing.T) {
+	as := assert.New(t)
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	_, err := gorequests.New(http.MethodGet, server.URL).WithLogger(gorequests.NewDiscardLogger()).Text()
+	as.NotNil(err)
+
+	text, err := gorequests.New(http.MethodGet, server.URL).WithIgnoreSSL(true).Text()
+	as.Nil(err)
+	as.Equal("ok", text)
+
+	t.Run("http.Transport", func(t *testing.T) {
+		text, err := gorequests.New(http.MethodGet, server.URL).WithTransport(&http.Transport{}).WithIgnoreSSL(true).Text()
+		as.Nil(err)
+		as.Equal("ok", text)
+	})
+
+	t.Run("other transport", func(t *testing.T) {
+		_, err := gorequests.New(http.MethodGet, server.URL).WithTransport(gorequests.NewHARTransport(&gorequests.HAR{})).WithIgnoreSSL(true).WithLogger(gorequests.NewDiscardLogger()).Text()
+		as.NotNil(err)
+		as.Contains(err.Error(), "ignore ssl is not supported by transport")
+	})
+}
@@ -1,36 +1,36 @@
 package gorequests
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"reflect"
 )
 
+// Unmarshal decode response with codec of Content-Type, default is json
 func (r *Request) Unmarshal(val interface{}) error {
-	bs, err := r.Bytes()
-	if err != nil {
-		return err
-	}
-	if err := json.Unmarshal(bs, val); err != nil {
-		return fmt.Errorf("[gorequest] %s %s unmarshal %s to %s failed: %w", r.method, r.cachedurl, bs, reflect.TypeOf(val).Name(), err)
-	}
-	return nil
+	return r.unmarshalResponse(nil, val)
 }
 
 func (r *Request) MustUnmarshal(val interface{}) {
 	_ = r.Unmarshal(val)
 }
 
-func (r *Request) Map() (map[string]interface{}, error) {
-	bs, err := r.Bytes()
-	if err != nil {
-		return nil, err
-	}
+// UnmarshalAs decode response with codec, ignore Content-Type
+func (r *Request) UnmarshalAs(codec Codec, val interface{}) error {
+	return r.unmarshalResponse(codec, val)
+}
 
+func (r *Request) MustUnmarshalAs(codec Codec, val interface{}) {
+	_ = r.UnmarshalAs(codec, val)
+}
+
+func (r *Request) Map() (map[string]interface{}, error) {
 	m := make(map[string]interface{})
-	if err := json.Unmarshal(bs, &m); err != nil {
-		return nil, fmt.Errorf("[gorequest] %s %s unmarshal %s to map failed: %w", r.method, r.cachedurl, bs, err)
+	if err := r.unmarshalResponse(nil, &m); err != nil {
+		return nil, err
 	}
 	return m, nil
 }
@@ -46,7 +46,7 @@ func (r *Request) Text() (string, error) {
 		return "", err
 	}
 
-	return string(bs), nil
+	return r.decodeText(bs)
 }
 
 func (r *Request) MustText() string {
@@ -148,3 +148,75 @@ func (r *Request) MustResponseHeaderByKey(key string) string {
 	val, _ := r.ResponseHeaderByKey(key)
 	return val
 }
+
+func (r *Request) ResponseFromCache() (bool, error) {
+	if err := r.doRequest(); err != nil {
+		return false, err
+	}
+
+	return r.resp.Header.Get(CacheHeader) == "1", nil
+}
+
+func (r *Request) MustResponseFromCache() bool {
+	val, _ := r.ResponseFromCache()
+	return val
+}
+
+// unmarshalResponse decode response with codec, nil codec means codec of Content-Type
+func (r *Request) unmarshalResponse(codec Codec, val interface{}) error {
+	if err := r.doRequest(); err != nil {
+		return err
+	}
+	if codec == nil {
+		codec = r.responseCodec()
+	}
+
+	if codec == JSONCodec && r.isStreamUnmarshal {
+		return r.doStream(func(body io.Reader) error {
+			if err := r.decodeJSON(body, val); err != nil {
+				return fmt.Errorf("[gorequest] %s %s unmarshal stream to %s failed: %w", r.method, r.cachedurl, reflect.TypeOf(val).Name(), err)
+			}
+			return nil
+		})
+	}
+
+	bs, err := r.Bytes()
+	if err != nil {
+		return err
+	}
+	if codec == JSONCodec && (r.jsonUseNumber || r.jsonDisallowUnknownFields) {
+		err = r.decodeJSON(bytes.NewReader(bs), val)
+	} else {
+		err = codec.Unmarshal(bs, val)
+	}
+	if err != nil {
+		return fmt.Errorf("[gorequest] %s %s unmarshal %s to %s failed: %w", r.method, r.cachedurl, bs, reflect.TypeOf(val).Name(), err)
+	}
+	return nil
+}
+
+// decodeJSON decode json with json.Decoder, and options of request, data after the json value is error like json.Unmarshal
+func (r *Request) decodeJSON(body io.Reader, val interface{}) error {
+	d := json.NewDecoder(body)
+	if r.jsonUseNumber {
+		d.UseNumber()
+	}
+	if r.jsonDisallowUnknownFields {
+		d.DisallowUnknownFields()
+	}
+	if err := d.Decode(val); err != nil {
+		return err
+	}
+	if err := d.Decode(&struct{}{}); err != io.EOF {
+		return fmt.Errorf("invalid data after top-level value at offset %d", d.InputOffset())
+	}
+	return nil
+}
+
+// responseCodec find codec by response Content-Type, json is used if not found
+func (r *Request) responseCodec() Codec {
+	if codec, ok := LookupCodec(r.resp.Header.Get("Content-Type")); ok {
+		return codec
+	}
+	return JSONCodec
+}
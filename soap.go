@@ -0,0 +1,281 @@
+package gorequests
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// SOAPVersion version of SOAP protocol
+type SOAPVersion int
+
+const (
+	SOAP11 SOAPVersion = iota // SOAP 1.1, Content-Type: text/xml, SOAPAction header
+	SOAP12                    // SOAP 1.2, Content-Type: application/soap+xml with action param
+)
+
+const (
+	soap11Namespace = "http://schemas.xmlsoap.org/soap/envelope/"
+	soap12Namespace = "http://www.w3.org/2003/05/soap-envelope"
+	wsseNamespace   = "http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-wssecurity-secext-1.0.xsd"
+	wsuNamespace    = "http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-wssecurity-utility-1.0.xsd"
+	wsseProfile     = "http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-username-token-profile-1.0"
+)
+
+// SOAPFault is the Fault element of SOAP response, returned as error by SOAPClient.Call
+type SOAPFault struct {
+	Code    string // faultcode of SOAP 1.1, Code/Value of SOAP 1.2
+	Subcode string // Code/Subcode/Value of SOAP 1.2
+	Reason  string // faultstring of SOAP 1.1, Reason/Text of SOAP 1.2
+	Actor   string // faultactor of SOAP 1.1, Role of SOAP 1.2
+	Detail  string // inner xml of detail
+}
+
+func (r *SOAPFault) Error() string {
+	code := r.Code
+	if r.Subcode != "" {
+		code += "/" + r.Subcode
+	}
+	return fmt.Sprintf("soap fault: %s: %s", code, r.Reason)
+}
+
+// SOAPClient send SOAP request to endpoint, created by Factory.SOAP
+type SOAPClient struct {
+	factory    *Factory
+	endpoint   string
+	version    SOAPVersion
+	wsSecurity *wsSecurity
+}
+
+// SOAP create SOAP client of endpoint, request is created by factory
+func (r *Factory) SOAP(endpoint string) *SOAPClient {
+	return &SOAPClient{factory: r, endpoint: endpoint}
+}
+
+// WithVersion set SOAP version, default is SOAP11
+func (r *SOAPClient) WithVersion(version SOAPVersion) *SOAPClient {
+	r.version = version
+	return r
+}
+
+// WithWSSecurity add WS-Security UsernameToken with PasswordText to SOAP header
+func (r *SOAPClient) WithWSSecurity(username, password string) *SOAPClient {
+	r.wsSecurity = &wsSecurity{username: username, password: password}
+	return r
+}
+
+// WithWSSecurityDigest add WS-Security UsernameToken with PasswordDigest to SOAP header
+func (r *SOAPClient) WithWSSecurityDigest(username, password string) *SOAPClient {
+	r.wsSecurity = &wsSecurity{username: username, password: password, digest: true}
+	return r
+}
+
+// Call wrap body in SOAP envelope and send it, then decode the first element of response SOAP Body to resp
+//
+// body support: []byte, string(as raw xml), interface{}(encoded as xml). resp can be nil.
+// Fault response is returned as *SOAPFault.
+func (r *SOAPClient) Call(ctx context.Context, action string, body, resp interface{}) error {
+	envelope, err := r.envelope(body)
+	if err != nil {
+		return fmt.Errorf("[gorequest] %s %s soap call failed: %w", http.MethodPost, r.endpoint, err)
+	}
+
+	req := r.factory.New(http.MethodPost, r.endpoint).WithContext(ctx).WithBody(envelope)
+	if r.version == SOAP12 {
+		req.WithHeader("Content-Type", fmt.Sprintf("application/soap+xml; charset=utf-8; action=%q", action))
+	} else {
+		req.WithHeader("Content-Type", "text/xml; charset=utf-8").WithHeader("SOAPAction", fmt.Sprintf("%q", action))
+	}
+	bs, err := req.Bytes()
+	if err != nil {
+		return err
+	}
+
+	// response is decoded by one decoder, so namespace prefix declared on Envelope can be used in Body
+	d := newXMLDecoder(bytes.NewReader(bs))
+	content, err := soapBodyContent(d)
+	if err != nil {
+		if req.resp.StatusCode >= 400 {
+			return fmt.Errorf("[gorequest] %s %s soap call failed, status=%d, body=%s", req.method, req.cachedurl, req.resp.StatusCode, bs)
+		}
+		return fmt.Errorf("[gorequest] %s %s unmarshal %s to soap envelope failed: %w", req.method, req.cachedurl, bs, err)
+	}
+	if content == nil {
+		return nil
+	}
+	if content.Name.Local == "Fault" {
+		fault := new(soapFaultXML)
+		if err := d.DecodeElement(fault, content); err != nil {
+			return fmt.Errorf("[gorequest] %s %s unmarshal soap fault failed: %w", req.method, req.cachedurl, err)
+		}
+		return fault.toSOAPFault()
+	}
+	if resp == nil {
+		return nil
+	}
+	if err := d.DecodeElement(resp, content); err != nil {
+		return fmt.Errorf("[gorequest] %s %s unmarshal soap body failed: %w", req.method, req.cachedurl, err)
+	}
+	return nil
+}
+
+func (r *SOAPClient) envelope(body interface{}) ([]byte, error) {
+	var content []byte
+	switch v := body.(type) {
+	case nil:
+	case []byte:
+		content = v
+	case string:
+		content = []byte(v)
+	default:
+		bs, err := xml.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		content = bs
+	}
+
+	namespace := soap11Namespace
+	if r.version == SOAP12 {
+		namespace = soap12Namespace
+	}
+
+	buf := new(bytes.Buffer)
+	buf.WriteString(xml.Header)
+	buf.WriteString(`<soap:Envelope xmlns:soap="` + namespace + `">`)
+	if r.wsSecurity != nil {
+		buf.WriteString("<soap:Header>")
+		if err := r.wsSecurity.write(buf); err != nil {
+			return nil, err
+		}
+		buf.WriteString("</soap:Header>")
+	}
+	buf.WriteString("<soap:Body>")
+	buf.Write(content)
+	buf.WriteString("</soap:Body></soap:Envelope>")
+	return buf.Bytes(), nil
+}
+
+type wsSecurity struct {
+	username string
+	password string
+	digest   bool
+}
+
+func (r *wsSecurity) write(buf *bytes.Buffer) error {
+	buf.WriteString(`<wsse:Security xmlns:wsse="` + wsseNamespace + `" xmlns:wsu="` + wsuNamespace + `" soap:mustUnderstand="1">`)
+	buf.WriteString("<wsse:UsernameToken><wsse:Username>")
+	_ = xml.EscapeText(buf, []byte(r.username))
+	buf.WriteString("</wsse:Username>")
+	if !r.digest {
+		buf.WriteString(`<wsse:Password Type="` + wsseProfile + `#PasswordText">`)
+		_ = xml.EscapeText(buf, []byte(r.password))
+		buf.WriteString("</wsse:Password>")
+	} else {
+		nonce := make([]byte, 16)
+		if _, err := rand.Read(nonce); err != nil {
+			return err
+		}
+		created := time.Now().UTC().Format("2006-01-02T15:04:05.000Z")
+		h := sha1.New()
+		h.Write(nonce)
+		h.Write([]byte(created))
+		h.Write([]byte(r.password))
+
+		buf.WriteString(`<wsse:Password Type="` + wsseProfile + `#PasswordDigest">` + base64.StdEncoding.EncodeToString(h.Sum(nil)) + "</wsse:Password>")
+		buf.WriteString(`<wsse:Nonce EncodingType="http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-soap-message-security-1.0#Base64Binary">` + base64.StdEncoding.EncodeToString(nonce) + "</wsse:Nonce>")
+		buf.WriteString("<wsu:Created>" + created + "</wsu:Created>")
+	}
+	buf.WriteString("</wsse:UsernameToken></wsse:Security>")
+	return nil
+}
+
+// soapBodyContent read d to the first element in Body of Envelope, nil if Body is empty or not exist
+func soapBodyContent(d *xml.Decoder) (*xml.StartElement, error) {
+	envelope, err := nextXMLElement(d)
+	if err != nil {
+		return nil, err
+	}
+	if envelope == nil || envelope.Name.Local != "Envelope" {
+		return nil, fmt.Errorf("root element is not Envelope")
+	}
+	for {
+		el, err := nextXMLElement(d)
+		if err != nil || el == nil {
+			return nil, err
+		}
+		if el.Name.Local == "Body" {
+			return nextXMLElement(d)
+		}
+		if err := d.Skip(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// nextXMLElement return next child element of current element, nil if current element is end
+func nextXMLElement(d *xml.Decoder) (*xml.StartElement, error) {
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch v := token.(type) {
+		case xml.StartElement:
+			return &v, nil
+		case xml.EndElement:
+			return nil, nil
+		}
+	}
+}
+
+// soapFaultXML contains fields of SOAP 1.1 and SOAP 1.2
+type soapFaultXML struct {
+	// SOAP 1.1
+	FaultCode   string `xml:"faultcode"`
+	FaultString string `xml:"faultstring"`
+	FaultActor  string `xml:"faultactor"`
+	FaultDetail struct {
+		Content string `xml:",innerxml"`
+	} `xml:"detail"`
+
+	// SOAP 1.2
+	Code struct {
+		Value   string `xml:"Value"`
+		Subcode struct {
+			Value string `xml:"Value"`
+		} `xml:"Subcode"`
+	} `xml:"Code"`
+	Reason struct {
+		Text []string `xml:"Text"`
+	} `xml:"Reason"`
+	Role   string `xml:"Role"`
+	Detail struct {
+		Content string `xml:",innerxml"`
+	} `xml:"Detail"`
+}
+
+func (r *soapFaultXML) toSOAPFault() *SOAPFault {
+	if r.FaultCode != "" || r.FaultString != "" {
+		return &SOAPFault{
+			Code:   strings.TrimSpace(r.FaultCode),
+			Reason: strings.TrimSpace(r.FaultString),
+			Actor:  strings.TrimSpace(r.FaultActor),
+			Detail: strings.TrimSpace(r.FaultDetail.Content),
+		}
+	}
+	return &SOAPFault{
+		Code:    strings.TrimSpace(r.Code.Value),
+		Subcode: strings.TrimSpace(r.Code.Subcode.Value),
+		Reason:  strings.TrimSpace(strings.Join(r.Reason.Text, "; ")),
+		Actor:   strings.TrimSpace(r.Role),
+		Detail:  strings.TrimSpace(r.Detail.Content),
+	}
+}
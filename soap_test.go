@@ -0,0 +1,98 @@
+package gorequests_test
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/chyroc/gorequests"
+	"github.com/stretchr/testify/assert"
+)
+
+type soapAdd struct {
+	XMLName xml.Name `xml:"http://tempuri.org/ Add"`
+	A       int      `xml:"intA"`
+	B       int      `xml:"intB"`
+}
+
+type soapAddResponse struct {
+	XMLName xml.Name `xml:"http://tempuri.org/ AddResponse"`
+	Result  int      `xml:"AddResult"`
+}
+
+func Test_SOAP(t *testing.T) {
+	as := assert.New(t)
+
+	var lastRequest string
+	var lastHeader http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bs, _ := ioutil.ReadAll(r.Body)
+		lastRequest, lastHeader = string(bs), r.Header
+		w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+		switch r.URL.Path {
+		case "/fault11":
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`<?xml version="1.0"?><soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><soap:Fault><faultcode>soap:Client</faultcode><faultstring>bad input</faultstring><detail><code>42</code></detail></soap:Fault></soap:Body></soap:Envelope>`))
+		case "/fault12":
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope"><env:Body><env:Fault><env:Code><env:Value>env:Sender</env:Value><env:Subcode><env:Value>m:Invalid</env:Value></env:Subcode></env:Code><env:Reason><env:Text xml:lang="en">bad input</env:Text></env:Reason></env:Fault></env:Body></env:Envelope>`))
+		case "/prefix":
+			_, _ = w.Write([]byte(`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/" xmlns:tns="http://tempuri.org/"><soap:Header><tns:Trace>1</tns:Trace></soap:Header><soap:Body><tns:AddResponse><tns:AddResult>5</tns:AddResult></tns:AddResponse></soap:Body></soap:Envelope>`))
+		default:
+			_, _ = w.Write([]byte(`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><AddResponse xmlns="http://tempuri.org/"><AddResult>3</AddResult></AddResponse></soap:Body></soap:Envelope>`))
+		}
+	}))
+	defer server.Close()
+
+	fac := gorequests.NewFactory(gorequests.WithLogger(gorequests.NewDiscardLogger()))
+	ctx := context.Background()
+
+	t.Run("soap 1.1", func(t *testing.T) {
+		resp := soapAddResponse{}
+		as.Nil(fac.SOAP(server.URL).WithWSSecurity("user", "p<ss").Call(ctx, "http://tempuri.org/Add", soapAdd{A: 1, B: 2}, &resp))
+		as.Equal(3, resp.Result)
+		as.Equal(`"http://tempuri.org/Add"`, lastHeader.Get("SOAPAction"))
+		as.Equal("text/xml; charset=utf-8", lastHeader.Get("Content-Type"))
+		as.Contains(lastRequest, `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">`)
+		as.Contains(lastRequest, `<wsse:Username>user</wsse:Username>`)
+		as.Contains(lastRequest, `#PasswordText">p&lt;ss</wsse:Password>`)
+		as.Contains(lastRequest, `<soap:Body><Add xmlns="http://tempuri.org/"><intA>1</intA><intB>2</intB></Add></soap:Body>`)
+	})
+
+	t.Run("soap 1.2", func(t *testing.T) {
+		resp := soapAddResponse{}
+		as.Nil(fac.SOAP(server.URL).WithVersion(gorequests.SOAP12).WithWSSecurityDigest("user", "pass").Call(ctx, "http://tempuri.org/Add", soapAdd{A: 1, B: 2}, &resp))
+		as.Equal(3, resp.Result)
+		as.Equal(`application/soap+xml; charset=utf-8; action="http://tempuri.org/Add"`, lastHeader.Get("Content-Type"))
+		as.Empty(lastHeader.Get("SOAPAction"))
+		as.Contains(lastRequest, `xmlns:soap="http://www.w3.org/2003/05/soap-envelope"`)
+		as.Contains(lastRequest, `#PasswordDigest">`)
+		as.True(strings.Contains(lastRequest, "<wsse:Nonce"))
+	})
+
+	t.Run("namespace prefix", func(t *testing.T) {
+		resp := soapAddResponse{}
+		as.Nil(fac.SOAP(server.URL+"/prefix").Call(ctx, "http://tempuri.org/Add", soapAdd{A: 2, B: 3}, &resp))
+		as.Equal(5, resp.Result)
+	})
+
+	t.Run("fault", func(t *testing.T) {
+		err := fac.SOAP(server.URL+"/fault11").Call(ctx, "Add", soapAdd{}, nil)
+		fault := new(gorequests.SOAPFault)
+		as.True(errors.As(err, &fault))
+		as.Equal("soap:Client", fault.Code)
+		as.Equal("bad input", fault.Reason)
+		as.Equal("<code>42</code>", fault.Detail)
+
+		err = fac.SOAP(server.URL+"/fault12").WithVersion(gorequests.SOAP12).Call(ctx, "Add", soapAdd{}, nil)
+		as.True(errors.As(err, &fault))
+		as.Equal("env:Sender", fault.Code)
+		as.Equal("m:Invalid", fault.Subcode)
+		as.Equal("soap fault: env:Sender/m:Invalid: bad input", err.Error())
+	})
+}
@@ -0,0 +1,233 @@
+package gorequests
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultSSERetry      = 3 * time.Second // reconnect delay of SSE, before server send retry field
+	defaultSSEMaxRetries = 10              // max times of consecutive failed reconnect
+	maxSSERetryDelay     = time.Minute     // max reconnect delay of backoff
+)
+
+// Event is one event of Server-Sent Events stream
+type Event struct {
+	ID    string        // last event id, inherited from previous event if not set
+	Event string        // event type, default is "message"
+	Data  string        // data lines joined with "\n"
+	Retry time.Duration // reconnect delay sent with the event, 0 if not set
+}
+
+// SSE read text/event-stream response and call f with every event
+//
+// it reconnects with Last-Event-ID header when connection is closed, until:
+//   - f return error, which is returned
+//   - request context is done, context error is returned
+//   - server response 204 No Content, nil is returned
+//   - server response non 200 status or non text/event-stream Content-Type
+//   - reconnect failed more than WithSSEMaxRetries times in a row, delay of failed reconnect is doubled every time
+//
+// Accept and Cache-Control headers are set if request is not sent yet.
+func (r *Request) SSE(f func(event Event) error) error {
+	r.lock.Lock()
+	if !r.isRequest {
+		if r.header.Get("Accept") == "" {
+			r.header.Set("Accept", "text/event-stream")
+		}
+		r.header.Set("Cache-Control", "no-cache")
+	}
+	r.lock.Unlock()
+
+	state := &sseState{retry: defaultSSERetry}
+	var reconnect bool
+	err := r.doStream(func(body io.Reader) error {
+		var err error
+		reconnect, err = r.readSSE(r.resp, body, state, f)
+		return err
+	})
+	failures := 0
+	for {
+		if err != nil || !reconnect {
+			return err
+		}
+		if r.rawBody == nil && r.body != nil {
+			return fmt.Errorf("[gorequest] %s %s sse reconnect failed: stream body can not be sent again", r.method, r.cachedurl)
+		}
+
+		select {
+		case <-r.Context().Done():
+			return r.Context().Err()
+		case <-time.After(sseRetryDelay(state.retry, failures)):
+		}
+
+		resp, connErr := r.reconnectSSE(state.lastEventID)
+		if connErr != nil {
+			r.logger.Error(r.Context(), "[gorequests] %s: %s, sse reconnect failed: %s", r.method, r.cachedurl, connErr)
+			if ctxErr := r.Context().Err(); ctxErr != nil {
+				return ctxErr
+			}
+			failures++
+			if r.sseMaxRetries >= 0 && failures > r.sseMaxRetries {
+				return fmt.Errorf("[gorequest] %s %s sse reconnect failed %d times: %w", r.method, r.cachedurl, failures, connErr)
+			}
+			continue
+		}
+		failures = 0
+		reconnect, err = r.readSSEResponse(resp, state, f)
+	}
+}
+
+// WithSSEMaxRetries set max times of consecutive failed reconnect of SSE, default is 10, negative means unlimited
+func (r *Request) WithSSEMaxRetries(n int) *Request {
+	return r.configParamFactor(func(r *Request) {
+		r.sseMaxRetries = n
+	})
+}
+
+// sseRetryDelay is retry doubled by every failure, and limited by maxSSERetryDelay
+func sseRetryDelay(retry time.Duration, failures int) time.Duration {
+	for i := 0; i < failures && retry < maxSSERetryDelay; i++ {
+		retry *= 2
+	}
+	if retry > maxSSERetryDelay {
+		return maxSSERetryDelay
+	}
+	return retry
+}
+
+// reconnectSSE send request again with Last-Event-ID header
+func (r *Request) reconnectSSE(lastEventID string) (*http.Response, error) {
+	var body io.Reader
+	if r.rawBody != nil {
+		body = bytes.NewReader(r.rawBody)
+	}
+	req, err := r.newHTTPRequest(body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header = req.Header.Clone()
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+
+	r.logger.Info(r.Context(), "[gorequests] %s: %s, sse reconnect, last-event-id=%s", r.method, r.cachedurl, lastEventID)
+	return r.httpClient().Do(req)
+}
+
+func (r *Request) readSSEResponse(resp *http.Response, state *sseState, f func(event Event) error) (bool, error) {
+	defer resp.Body.Close()
+
+	r.lock.Lock()
+	r.resp = resp
+	r.lock.Unlock()
+
+	body, err := r.responseBody()
+	if err != nil {
+		return false, err
+	}
+	return r.readSSE(resp, body, state, f)
+}
+
+// readSSE read events of one connection, and return whether should reconnect
+func (r *Request) readSSE(resp *http.Response, body io.Reader, state *sseState, f func(event Event) error) (bool, error) {
+	if resp.StatusCode == http.StatusNoContent {
+		return false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		bs, _ := ioutil.ReadAll(io.LimitReader(body, 1024))
+		return false, fmt.Errorf("[gorequest] %s %s sse failed, status=%d, body=%s", r.method, r.cachedurl, resp.StatusCode, bs)
+	}
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType != "text/event-stream" {
+		return false, fmt.Errorf("[gorequest] %s %s sse failed, unexpected Content-Type: %q", r.method, r.cachedurl, resp.Header.Get("Content-Type"))
+	}
+
+	if err := state.read(body, f); err != nil {
+		return false, err
+	}
+	if err := r.Context().Err(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// sseState is parse state kept between connections
+type sseState struct {
+	lastEventID string
+	retry       time.Duration
+}
+
+// read parse event stream and call f, read error is ignored, so stream can be reconnected
+func (r *sseState) read(body io.Reader, f func(event Event) error) error {
+	var (
+		br        = bufio.NewReader(body)
+		data      strings.Builder
+		hasData   bool
+		eventType string
+		eventID   = r.lastEventID
+		retry     time.Duration
+		first     = true
+	)
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil && (line == "" || err != io.EOF) {
+			// incomplete event is discarded
+			return nil
+		}
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+		if first {
+			line = strings.TrimPrefix(line, "\ufeff")
+			first = false
+		}
+
+		if line == "" {
+			// id of incomplete event is not used to reconnect
+			r.lastEventID = eventID
+			if hasData {
+				event := Event{ID: eventID, Event: eventType, Data: strings.TrimSuffix(data.String(), "\n"), Retry: retry}
+				if event.Event == "" {
+					event.Event = "message"
+				}
+				if err := f(event); err != nil {
+					return err
+				}
+			}
+			data.Reset()
+			hasData, eventType, retry = false, "", 0
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value := line, ""
+		if i := strings.IndexByte(line, ':'); i >= 0 {
+			field, value = line[:i], strings.TrimPrefix(line[i+1:], " ")
+		}
+		switch field {
+		case "event":
+			eventType = value
+		case "data":
+			data.WriteString(value)
+			data.WriteByte('\n')
+			hasData = true
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				eventID = value
+			}
+		case "retry":
+			if ms, err := strconv.ParseUint(value, 10, 63); err == nil {
+				retry = time.Duration(ms) * time.Millisecond
+				r.retry = retry
+			}
+		}
+	}
+}
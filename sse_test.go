@@ -0,0 +1,135 @@
+package gorequests_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/chyroc/gorequests"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_SSE(t *testing.T) {
+	as := assert.New(t)
+
+	var connections int32
+	var lastEventIDs []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&connections, 1)
+		lastEventIDs = append(lastEventIDs, r.Header.Get("Last-Event-ID"))
+		switch r.Header.Get("Last-Event-ID") {
+		case "":
+			w.Header().Set("Content-Type", "text/event-stream; charset=utf-8")
+			_, _ = fmt.Fprint(w, ": comment\nretry: 10\n\nid: 1\ndata: hello\ndata:world\n\nevent: update\ndata: {\"a\":1}\r\n\r\ndata: no id\n\nid: 2\ndata: incomplete")
+		case "1":
+			w.Header().Set("Content-Type", "text/event-stream")
+			_, _ = fmt.Fprint(w, "id: 3\nevent: done\ndata: bye\n\n")
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	t.Run("reconnect", func(t *testing.T) {
+		var events []gorequests.Event
+		err := gorequests.New(http.MethodGet, server.URL).WithLogger(gorequests.NewDiscardLogger()).SSE(func(event gorequests.Event) error {
+			events = append(events, event)
+			return nil
+		})
+		as.Nil(err)
+		as.Equal([]gorequests.Event{
+			{ID: "1", Event: "message", Data: "hello\nworld"},
+			{ID: "1", Event: "update", Data: `{"a":1}`},
+			{ID: "1", Event: "message", Data: "no id"},
+			{ID: "3", Event: "done", Data: "bye"},
+		}, events)
+		as.Equal([]string{"", "1", "3"}, lastEventIDs)
+		as.Equal(int32(3), atomic.LoadInt32(&connections))
+	})
+
+	t.Run("stop by callback", func(t *testing.T) {
+		stop := errors.New("stop")
+		count := 0
+		err := gorequests.New(http.MethodGet, server.URL).SSE(func(event gorequests.Event) error {
+			count++
+			return stop
+		})
+		as.Equal(stop, err)
+		as.Equal(1, count)
+	})
+
+	t.Run("stop by context", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			_, _ = fmt.Fprint(w, "retry: 1\ndata: ping\n\n")
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		}))
+		defer server.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*200)
+		defer cancel()
+		count := 0
+		err := gorequests.New(http.MethodGet, server.URL).WithContext(ctx).SSE(func(event gorequests.Event) error {
+			count++
+			return nil
+		})
+		as.True(errors.Is(err, context.DeadlineExceeded), err)
+		as.Equal(1, count)
+	})
+
+	t.Run("max retries", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			_, _ = fmt.Fprint(w, "retry: 1\ndata: ping\n\n")
+		}))
+		defer server.Close()
+
+		// endpoint is dead after first connection
+		dead := errors.New("dead")
+		var calls int32
+		count := 0
+		err := gorequests.New(http.MethodGet, server.URL).WithLogger(gorequests.NewDiscardLogger()).WithSSEMaxRetries(2).WithWrapTransport(func(rt http.RoundTripper) http.RoundTripper {
+			return sseRoundTripper(func(req *http.Request) (*http.Response, error) {
+				if atomic.AddInt32(&calls, 1) > 1 {
+					return nil, dead
+				}
+				return rt.RoundTrip(req)
+			})
+		}).SSE(func(event gorequests.Event) error {
+			count++
+			return nil
+		})
+		as.True(errors.Is(err, dead), err)
+		as.Contains(err.Error(), "sse reconnect failed 3 times")
+		as.Equal(int32(4), atomic.LoadInt32(&calls))
+		as.Equal(1, count)
+	})
+
+	t.Run("already sent", func(t *testing.T) {
+		req := gorequests.New(http.MethodGet, server.URL).WithHeader("Last-Event-ID", "2")
+		as.Equal(http.StatusNoContent, req.MustResponseStatus())
+		as.Nil(req.SSE(func(event gorequests.Event) error { return nil }))
+	})
+
+	t.Run("not event stream", func(t *testing.T) {
+		err := gorequests.New(http.MethodGet, joinHttpBinURL("/status/500")).SSE(func(event gorequests.Event) error { return nil })
+		as.NotNil(err)
+		as.Contains(err.Error(), "status=500")
+
+		err = gorequests.New(http.MethodGet, joinHttpBinURL("/get")).SSE(func(event gorequests.Event) error { return nil })
+		as.NotNil(err)
+		as.Contains(err.Error(), "Content-Type")
+	})
+}
+
+type sseRoundTripper func(req *http.Request) (*http.Response, error)
+
+func (r sseRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	return r(req)
+}
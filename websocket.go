@@ -0,0 +1,106 @@
+package gorequests
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/websocket"
+)
+
+// websocketHandshakeHeaders is set by websocket dialer, and can not be set by request
+var websocketHandshakeHeaders = []string{
+	"Upgrade",
+	"Connection",
+	"Sec-Websocket-Key",
+	"Sec-Websocket-Version",
+	"Sec-Websocket-Extensions",
+}
+
+// WebSocket do websocket handshake with request url, header, cookie jar, tls and proxy setting, and return connection
+//
+// http(s) scheme is converted to ws(s), permessage-deflate compression is negotiated.
+// handshake response can be get by Response after dial.
+func (r *Request) WebSocket() (*websocket.Conn, error) {
+	var conn *websocket.Conn
+	err := r.doRequestFactor(func() error {
+		if r.isRequest {
+			return fmt.Errorf("[gorequest] %s %s request alreday sended, cannot upgrade to websocket", r.method, r.cachedurl)
+		}
+
+		cachedurl, err := r.parseRequestURL()
+		r.cachedurl = cachedurl
+		if err != nil {
+			return err
+		}
+		wsURL := r.cachedurl
+		if strings.HasPrefix(wsURL, "http://") {
+			wsURL = "ws://" + strings.TrimPrefix(wsURL, "http://")
+		} else if strings.HasPrefix(wsURL, "https://") {
+			wsURL = "wss://" + strings.TrimPrefix(wsURL, "https://")
+		}
+
+		r.logger.Info(r.Context(), "[gorequests] websocket: %s, header=%+v", wsURL, r.header)
+
+		if r.persistentJar != nil {
+			defer func() {
+				if err := r.persistentJar.Save(); err != nil {
+					r.logger.Error(r.Context(), "save cookie failed: %s", err)
+				}
+			}()
+		}
+
+		header := r.header.Clone()
+		for _, k := range websocketHandshakeHeaders {
+			header.Del(k)
+		}
+
+		var resp *http.Response
+		conn, resp, err = r.websocketDialer().DialContext(r.Context(), wsURL, header)
+		r.resp = resp
+		r.isRequest = true
+		if err != nil {
+			if resp != nil {
+				bs, _ := ioutil.ReadAll(resp.Body)
+				r.bytes, r.isRead = bs, true
+				return fmt.Errorf("[gorequest] %s %s websocket handshake failed, status=%d, body=%s: %w", r.method, r.cachedurl, resp.StatusCode, bs, err)
+			}
+			return fmt.Errorf("[gorequest] %s %s websocket handshake failed: %w", r.method, r.cachedurl, err)
+		}
+		return nil
+	})
+	return conn, err
+}
+
+func (r *Request) MustWebSocket() *websocket.Conn {
+	val, _ := r.WebSocket()
+	return val
+}
+
+// websocketDialer build websocket.Dialer, proxy and tls config of *http.Transport is reused
+func (r *Request) websocketDialer() *websocket.Dialer {
+	dialer := &websocket.Dialer{
+		Proxy:             http.ProxyFromEnvironment,
+		HandshakeTimeout:  r.timeout,
+		EnableCompression: true,
+	}
+	if r.persistentJar != nil {
+		dialer.Jar = r.persistentJar
+	}
+	if t, ok := r.transport.(*http.Transport); ok {
+		dialer.Proxy = t.Proxy
+		dialer.NetDialContext = t.DialContext
+		if t.TLSClientConfig != nil {
+			dialer.TLSClientConfig = t.TLSClientConfig.Clone()
+		}
+	}
+	if r.isIgnoreSSL {
+		if dialer.TLSClientConfig == nil {
+			dialer.TLSClientConfig = &tls.Config{}
+		}
+		dialer.TLSClientConfig.InsecureSkipVerify = true
+	}
+	return dialer
+}
@@ -0,0 +1,94 @@
+package gorequests_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/chyroc/gorequests"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_WebSocket(t *testing.T) {
+	as := assert.New(t)
+
+	upgrader := websocket.Upgrader{EnableCompression: true}
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			http.SetCookie(w, &http.Cookie{Name: "token", Value: "abc", Path: "/"})
+			return
+		case "/forbidden":
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+
+		cookie, _ := r.Cookie("token")
+		header := http.Header{}
+		header.Set("X-Echo", r.Header.Get("X-Auth")+","+cookie.Value)
+		conn, err := upgrader.Upgrade(w, r, header)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			mt, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if err := conn.WriteMessage(mt, append([]byte("echo:"), msg...)); err != nil {
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	file, err := ioutil.TempFile("", "gorequests-websocket-*.txt")
+	as.Nil(err)
+	defer os.Remove(file.Name())
+	session := gorequests.NewSession(file.Name(), gorequests.WithLogger(gorequests.NewDiscardLogger()), func(req *gorequests.Request) error {
+		req.WithIgnoreSSL(true)
+		return nil
+	})
+
+	t.Run("echo", func(t *testing.T) {
+		_, err := session.New(http.MethodGet, server.URL+"/login").Bytes()
+		as.Nil(err)
+
+		req := session.New(http.MethodGet, server.URL+"/ws").WithHeader("X-Auth", "1")
+		conn, err := req.WebSocket()
+		as.Nil(err)
+		defer conn.Close()
+
+		as.Equal("1,abc", req.MustResponseHeaderByKey("X-Echo"))
+		as.Equal(http.StatusSwitchingProtocols, req.MustResponseStatus())
+		as.Contains(req.MustResponseHeaderByKey("Sec-Websocket-Extensions"), "permessage-deflate")
+
+		as.Nil(conn.WriteMessage(websocket.TextMessage, []byte(strings.Repeat("hi", 100))))
+		mt, msg, err := conn.ReadMessage()
+		as.Nil(err)
+		as.Equal(websocket.TextMessage, mt)
+		as.Equal("echo:"+strings.Repeat("hi", 100), string(msg))
+
+		as.Nil(conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")))
+	})
+
+	t.Run("handshake failed", func(t *testing.T) {
+		req := session.New(http.MethodGet, server.URL+"/forbidden")
+		_, err := req.WebSocket()
+		as.NotNil(err)
+		as.Contains(err.Error(), "status=403")
+	})
+
+	t.Run("already sended", func(t *testing.T) {
+		req := session.New(http.MethodGet, server.URL+"/login")
+		_, err := req.Bytes()
+		as.Nil(err)
+		_, err = req.WebSocket()
+		as.NotNil(err)
+	})
+}
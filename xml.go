@@ -0,0 +1,58 @@
+package gorequests
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+
+	"golang.org/x/net/html/charset"
+)
+
+// UnmarshalAsXML decode response as xml, ignore Content-Type
+//
+// it's not named UnmarshalXML, which is reserved for xml.Unmarshaler.
+func (r *Request) UnmarshalAsXML(val interface{}) error {
+	return r.UnmarshalAs(XMLCodec, val)
+}
+
+func (r *Request) MustUnmarshalAsXML(val interface{}) {
+	_ = r.UnmarshalAsXML(val)
+}
+
+// XMLDecoder call f with xml.Decoder of response body, body is decoded by stream without buffering
+func (r *Request) XMLDecoder(f func(d *xml.Decoder) error) error {
+	return r.doStream(func(body io.Reader) error {
+		return f(newXMLDecoder(body))
+	})
+}
+
+// EachXMLElement call f with every element named name by stream, empty name.Space match any namespace
+//
+// f can use d.DecodeElement(&v, &start) to decode the element.
+func (r *Request) EachXMLElement(name xml.Name, f func(d *xml.Decoder, start xml.StartElement) error) error {
+	return r.XMLDecoder(func(d *xml.Decoder) error {
+		for {
+			token, err := d.Token()
+			if err == io.EOF {
+				return nil
+			} else if err != nil {
+				return fmt.Errorf("[gorequest] %s %s decode xml failed: %w", r.method, r.cachedurl, err)
+			}
+
+			start, ok := token.(xml.StartElement)
+			if !ok || start.Name.Local != name.Local || (name.Space != "" && start.Name.Space != name.Space) {
+				continue
+			}
+			if err := f(d, start); err != nil {
+				return err
+			}
+		}
+	})
+}
+
+// newXMLDecoder create xml.Decoder which support non utf-8 encoding declaration
+func newXMLDecoder(r io.Reader) *xml.Decoder {
+	d := xml.NewDecoder(r)
+	d.CharsetReader = charset.NewReaderLabel
+	return d
+}
@@ -0,0 +1,85 @@
+package gorequests_test
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chyroc/gorequests"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+type xmlItem struct {
+	XMLName xml.Name `xml:"urn:feed item"`
+	ID      int      `xml:"id"`
+	Title   string   `xml:"title"`
+}
+
+func Test_XML(t *testing.T) {
+	as := assert.New(t)
+
+	feed := `<?xml version="1.0" encoding="UTF-8"?>
+<f:feed xmlns:f="urn:feed" xmlns:o="urn:other">
+  <f:item><f:id>1</f:id><f:title>a</f:title></f:item>
+  <o:item><o:id>2</o:id></o:item>
+  <f:item><f:id>3</f:id><f:title>c</f:title></f:item>
+</f:feed>`
+	gbk, _ := simplifiedchinese.GBK.NewEncoder().String(`<?xml version="1.0" encoding="GBK"?><item xmlns="urn:feed"><id>1</id><title>你好</title></item>`)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/feed":
+			w.Header().Set("Content-Type", "application/atom+xml")
+			_, _ = w.Write([]byte(feed))
+		case "/gbk":
+			w.Header().Set("Content-Type", "text/plain")
+			_, _ = w.Write([]byte(gbk))
+		default:
+			bs, _ := ioutil.ReadAll(r.Body)
+			w.Header().Set("Content-Type", r.Header.Get("Content-Type"))
+			_, _ = w.Write(bs)
+		}
+	}))
+	defer server.Close()
+
+	fac := gorequests.NewFactory(gorequests.WithLogger(gorequests.NewDiscardLogger()))
+
+	t.Run("WithXML", func(t *testing.T) {
+		req := fac.New(http.MethodPost, server.URL).WithXML(xmlItem{ID: 1, Title: "a"})
+		resp := xmlItem{}
+		as.Nil(req.Unmarshal(&resp))
+		as.Equal("application/xml", req.MustResponseHeaderByKey("Content-Type"))
+		as.Equal(1, resp.ID)
+		as.Equal("urn:feed", resp.XMLName.Space)
+	})
+
+	t.Run("UnmarshalAsXML", func(t *testing.T) {
+		resp := xmlItem{}
+		as.Nil(fac.New(http.MethodGet, server.URL+"/gbk").UnmarshalAsXML(&resp))
+		as.Equal("你好", resp.Title)
+	})
+
+	t.Run("EachXMLElement", func(t *testing.T) {
+		var items []xmlItem
+		err := fac.New(http.MethodGet, server.URL+"/feed").EachXMLElement(xml.Name{Space: "urn:feed", Local: "item"}, func(d *xml.Decoder, start xml.StartElement) error {
+			item := xmlItem{}
+			if err := d.DecodeElement(&item, &start); err != nil {
+				return err
+			}
+			items = append(items, item)
+			return nil
+		})
+		as.Nil(err)
+		as.Equal([]int{1, 3}, []int{items[0].ID, items[1].ID})
+
+		count := 0
+		as.Nil(fac.New(http.MethodGet, server.URL+"/feed").EachXMLElement(xml.Name{Local: "id"}, func(d *xml.Decoder, start xml.StartElement) error {
+			count++
+			return nil
+		}))
+		as.Equal(3, count)
+	})
+}